@@ -13,7 +13,12 @@ import (
 // @Schemes http
 // @BasePath  /api/v1
 func main() {
-	configServer := config.New()
+	configServer, err := config.New()
+	if err != nil {
+		fmt.Printf("Error in config: %s", err.Error())
+
+		return
+	}
 
 	srv := new(server.Server)
 	if err := srv.Run(configServer); err != nil {
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS sync_tags_feature_id ON public."banner";
+DROP FUNCTION IF EXISTS banner_sync_tags_feature_id;
+
+DROP TRIGGER IF EXISTS set_feature_id ON public."banner_tag";
+DROP FUNCTION IF EXISTS banner_tag_set_feature_id;
+
+DROP INDEX IF EXISTS banner_tag_feature_id_tag_id_uniq;
+
+ALTER TABLE public."banner_tag"
+    DROP COLUMN IF EXISTS feature_id;
@@ -0,0 +1,68 @@
+ALTER TABLE public."banner_tag"
+    ADD COLUMN IF NOT EXISTS feature_id BIGINT REFERENCES public."feature" (id);
+
+UPDATE public."banner_tag" bt
+SET feature_id = b.feature_id
+FROM public."banner" b
+WHERE bt.banner_id = b.id;
+
+ALTER TABLE public."banner_tag"
+    ALTER COLUMN feature_id SET NOT NULL;
+
+-- one feature and tag pair must lead to one banner. Existing duplicates can not be resolved
+-- automatically, because it is not known which banner must be kept: migration fails and lists them,
+-- they must be cleaned up manually (delete extra banners or their tags) before migration is run again
+DO
+$$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT STRING_AGG(FORMAT('feature_id=%s tag_id=%s banner_ids=%s', feature_id, tag_id, banner_ids), '; ')
+    INTO duplicates
+    FROM (SELECT feature_id, tag_id, ARRAY_AGG(banner_id ORDER BY banner_id) AS banner_ids
+          FROM public."banner_tag"
+          GROUP BY feature_id, tag_id
+          HAVING COUNT(*) > 1) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'banner_tag has several banners for the same feature and tag, delete extra ones first: %',
+            duplicates;
+    END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS banner_tag_feature_id_tag_id_uniq
+    ON public."banner_tag" (feature_id, tag_id);
+
+CREATE OR REPLACE FUNCTION banner_tag_set_feature_id()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    SELECT feature_id INTO NEW.feature_id FROM public."banner" WHERE id = NEW.banner_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_feature_id ON public."banner_tag";
+CREATE TRIGGER set_feature_id
+    BEFORE INSERT OR UPDATE OF banner_id
+    ON public."banner_tag"
+    FOR EACH ROW
+EXECUTE PROCEDURE banner_tag_set_feature_id();
+
+CREATE OR REPLACE FUNCTION banner_sync_tags_feature_id()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE public."banner_tag" SET feature_id = NEW.feature_id WHERE banner_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS sync_tags_feature_id ON public."banner";
+CREATE TRIGGER sync_tags_feature_id
+    AFTER UPDATE OF feature_id
+    ON public."banner"
+    FOR EACH ROW
+    WHEN (OLD.feature_id IS DISTINCT FROM NEW.feature_id)
+EXECUTE PROCEDURE banner_sync_tags_feature_id();
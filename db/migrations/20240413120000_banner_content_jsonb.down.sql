@@ -0,0 +1,18 @@
+ALTER TABLE public."banner"
+    ADD COLUMN IF NOT EXISTS title TEXT,
+    ADD COLUMN IF NOT EXISTS text  TEXT,
+    ADD COLUMN IF NOT EXISTS url   TEXT;
+
+UPDATE public."banner"
+SET title = COALESCE(content ->> 'title', ''),
+    text  = COALESCE(content ->> 'text', ''),
+    url   = COALESCE(content ->> 'url', '');
+
+ALTER TABLE public."banner"
+    ALTER COLUMN title SET NOT NULL,
+    ALTER COLUMN text SET NOT NULL,
+    ALTER COLUMN url SET NOT NULL;
+
+ALTER TABLE public."banner"
+    DROP CONSTRAINT IF EXISTS content_is_object,
+    DROP COLUMN IF EXISTS content;
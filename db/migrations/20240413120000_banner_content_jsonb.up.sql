@@ -0,0 +1,14 @@
+ALTER TABLE public."banner"
+    ADD COLUMN IF NOT EXISTS content JSONB;
+
+UPDATE public."banner"
+SET content = JSONB_BUILD_OBJECT('title', title, 'text', text, 'url', url);
+
+ALTER TABLE public."banner"
+    ALTER COLUMN content SET NOT NULL,
+    ADD CONSTRAINT content_is_object CHECK (JSONB_TYPEOF(content) = 'object');
+
+ALTER TABLE public."banner"
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS text,
+    DROP COLUMN IF EXISTS url;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public."feature_schema" CASCADE;
+
+DROP SEQUENCE IF EXISTS feature_schema_id_seq;
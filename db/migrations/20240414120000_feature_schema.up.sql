@@ -0,0 +1,12 @@
+CREATE SEQUENCE IF NOT EXISTS feature_schema_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."feature_schema"
+(
+    id           BIGINT                   DEFAULT NEXTVAL('feature_schema_id_seq'::regclass)  NOT NULL PRIMARY KEY,
+    feature_id   BIGINT                                                                       NOT NULL REFERENCES public."feature" (id) ON DELETE CASCADE,
+    version      BIGINT                                                                       NOT NULL CHECK (version > 0),
+    schema       JSONB                                                                        NOT NULL,
+    author_id    BIGINT                                                                       NOT NULL REFERENCES public."user" (id),
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                       NOT NULL,
+    UNIQUE (feature_id, version)
+);
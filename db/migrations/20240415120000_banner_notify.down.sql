@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS notify_banner_change ON public."banner_tag";
+DROP TRIGGER IF EXISTS notify_banner_change ON public."banner";
+DROP FUNCTION IF EXISTS notify_banner_change;
@@ -0,0 +1,24 @@
+-- Statement level triggers: postgres folds equal notifications of one transaction,
+-- so a bulk change of banners produces a single event per table and operation.
+CREATE OR REPLACE FUNCTION notify_banner_change()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM PG_NOTIFY('banner_changes', JSON_BUILD_OBJECT('table', TG_TABLE_NAME, 'op', TG_OP)::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS notify_banner_change ON public."banner";
+CREATE TRIGGER notify_banner_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON public."banner"
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE notify_banner_change();
+
+DROP TRIGGER IF EXISTS notify_banner_change ON public."banner_tag";
+CREATE TRIGGER notify_banner_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON public."banner_tag"
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE notify_banner_change();
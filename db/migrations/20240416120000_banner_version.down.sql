@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public."banner_version" CASCADE;
+
+DROP SEQUENCE IF EXISTS banner_version_id_seq;
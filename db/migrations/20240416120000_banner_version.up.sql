@@ -0,0 +1,28 @@
+CREATE SEQUENCE IF NOT EXISTS banner_version_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."banner_version"
+(
+    id           BIGINT                   DEFAULT NEXTVAL('banner_version_id_seq'::regclass)  NOT NULL PRIMARY KEY,
+    banner_id    BIGINT                                                                       NOT NULL REFERENCES public."banner" (id) ON DELETE CASCADE,
+    version      BIGINT                                                                       NOT NULL CHECK (version > 0),
+    author_id    BIGINT                                                                       NOT NULL REFERENCES public."user" (id),
+    feature_id   BIGINT                                                                       NOT NULL,
+    tag_ids      BIGINT[]                                                                     NOT NULL,
+    content      JSONB                                                                        NOT NULL,
+    is_active    BOOL                                                                         NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                       NOT NULL,
+    UNIQUE (banner_id, version)
+);
+
+INSERT INTO public."banner_version" (banner_id, version, author_id, feature_id, tag_ids, content, is_active, created_at)
+SELECT b.id,
+       1,
+       b.author_id,
+       b.feature_id,
+       COALESCE(ARRAY_AGG(bt.tag_id ORDER BY bt.tag_id) FILTER (WHERE bt.tag_id IS NOT NULL), '{}'),
+       b.content,
+       b.is_active,
+       b.updated_at
+FROM public."banner" b
+         LEFT JOIN public."banner_tag" bt ON b.id = bt.banner_id
+GROUP BY b.id;
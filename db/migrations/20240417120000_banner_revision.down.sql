@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS increment_revision ON public."banner";
+DROP FUNCTION IF EXISTS revision_increment;
+
+ALTER TABLE public."banner"
+    DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,18 @@
+ALTER TABLE public."banner"
+    ADD COLUMN IF NOT EXISTS revision BIGINT DEFAULT 1 NOT NULL;
+
+CREATE OR REPLACE FUNCTION revision_increment()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.revision = OLD.revision + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS increment_revision ON public."banner";
+CREATE TRIGGER increment_revision
+    BEFORE UPDATE
+    ON public."banner"
+    FOR EACH ROW
+EXECUTE PROCEDURE revision_increment();
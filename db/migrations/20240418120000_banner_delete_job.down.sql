@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS public."banner_delete_job";
+DROP SEQUENCE IF EXISTS banner_delete_job_id_seq;
+
+ALTER TABLE public."banner_tag"
+    DROP CONSTRAINT IF EXISTS banner_tag_banner_id_fkey,
+    ADD CONSTRAINT banner_tag_banner_id_fkey
+        FOREIGN KEY (banner_id) REFERENCES public."banner" (id);
@@ -0,0 +1,32 @@
+-- tags of banner go away with it, so deleting banner does not need to delete them first
+ALTER TABLE public."banner_tag"
+    DROP CONSTRAINT IF EXISTS banner_tag_banner_id_fkey,
+    ADD CONSTRAINT banner_tag_banner_id_fkey
+        FOREIGN KEY (banner_id) REFERENCES public."banner" (id) ON DELETE CASCADE;
+
+CREATE SEQUENCE IF NOT EXISTS banner_delete_job_id_seq;
+
+CREATE TABLE IF NOT EXISTS public."banner_delete_job"
+(
+    id            BIGINT                   DEFAULT NEXTVAL('banner_delete_job_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    author_id     BIGINT                                                                         NOT NULL REFERENCES public."user" (id),
+    feature_id    BIGINT,
+    tag_id        BIGINT,
+    status        TEXT                     DEFAULT 'pending'                                     NOT NULL
+        CONSTRAINT status_is_known CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    deleted_count BIGINT                   DEFAULT 0                                             NOT NULL,
+    error         TEXT                     DEFAULT ''                                            NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                         NOT NULL,
+    updated_at    TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                         NOT NULL,
+    CONSTRAINT feature_or_tag CHECK (NUM_NONNULLS(feature_id, tag_id) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS banner_delete_job_unfinished_idx
+    ON public."banner_delete_job" (id) WHERE status IN ('pending', 'running');
+
+DROP TRIGGER IF EXISTS verify_updated_at ON public."banner_delete_job";
+CREATE TRIGGER verify_updated_at
+    BEFORE UPDATE
+    ON public."banner_delete_job"
+    FOR EACH ROW
+EXECUTE PROCEDURE updated_at_now();
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS banner_tag_tag_id_idx;
+DROP INDEX IF EXISTS tag_title_prefix_idx;
+
+ALTER TABLE public."tag"
+    DROP COLUMN IF EXISTS description;
@@ -0,0 +1,8 @@
+ALTER TABLE public."tag"
+    ADD COLUMN IF NOT EXISTS description TEXT DEFAULT '' NOT NULL
+        CONSTRAINT max_len_description CHECK (LENGTH(description) <= 1000);
+
+-- text_pattern_ops lets LIKE 'prefix%' use index whatever collation is
+CREATE INDEX IF NOT EXISTS tag_title_prefix_idx ON public."tag" (title text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS banner_tag_tag_id_idx ON public."banner_tag" (tag_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public."revoked_access_token";
+DROP TABLE IF EXISTS public."refresh_token";
+DROP SEQUENCE IF EXISTS refresh_token_id_seq;
@@ -0,0 +1,29 @@
+CREATE SEQUENCE IF NOT EXISTS refresh_token_id_seq;
+
+-- refresh tokens are stored only as sha256 of them. Tokens of one sign in share family_id,
+-- access_jti is id of access token issued together with refresh token
+CREATE TABLE IF NOT EXISTS public."refresh_token"
+(
+    id                BIGINT                   DEFAULT NEXTVAL('refresh_token_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    user_id           BIGINT                                                                     NOT NULL REFERENCES public."user" (id) ON DELETE CASCADE,
+    family_id         TEXT                                                                       NOT NULL CHECK (family_id <> ''),
+    token_hash        TEXT UNIQUE                                                                NOT NULL CHECK (token_hash <> ''),
+    access_jti        TEXT                                                                       NOT NULL CHECK (access_jti <> ''),
+    access_expires_at TIMESTAMP WITH TIME ZONE                                                   NOT NULL,
+    expires_at        TIMESTAMP WITH TIME ZONE                                                   NOT NULL,
+    used_at           TIMESTAMP WITH TIME ZONE,
+    revoked_at        TIMESTAMP WITH TIME ZONE,
+    created_at        TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                     NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS refresh_token_family_id_idx ON public."refresh_token" (family_id);
+CREATE INDEX IF NOT EXISTS refresh_token_user_id_idx ON public."refresh_token" (user_id);
+
+-- access tokens which are revoked before they expire
+CREATE TABLE IF NOT EXISTS public."revoked_access_token"
+(
+    jti        TEXT                     NOT NULL PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS revoked_access_token_expires_at_idx ON public."revoked_access_token" (expires_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public."role_binding";
+DROP SEQUENCE IF EXISTS role_binding_id_seq;
@@ -0,0 +1,12 @@
+CREATE SEQUENCE IF NOT EXISTS role_binding_id_seq;
+
+-- role of user on banners of feature, feature_id IS NULL means role on banners of all features
+CREATE TABLE IF NOT EXISTS public."role_binding"
+(
+    id         BIGINT                   DEFAULT NEXTVAL('role_binding_id_seq'::regclass) NOT NULL PRIMARY KEY,
+    user_id    BIGINT                                                                    NOT NULL REFERENCES public."user" (id) ON DELETE CASCADE,
+    role       TEXT                                                                      NOT NULL CHECK (role <> ''),
+    feature_id BIGINT                                                                    REFERENCES public."feature" (id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()                                    NOT NULL,
+    CONSTRAINT uniq_role_binding UNIQUE NULLS NOT DISTINCT (user_id, role, feature_id)
+);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS notify_access_token_revoked ON public."revoked_access_token";
+DROP FUNCTION IF EXISTS notify_access_token_revoked;
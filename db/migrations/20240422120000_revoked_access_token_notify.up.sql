@@ -0,0 +1,17 @@
+-- Every instance of service keeps revoked access tokens in memory, so each revoked token
+-- is sent to all of them. Row level trigger: payload is the token itself.
+CREATE OR REPLACE FUNCTION notify_access_token_revoked()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM PG_NOTIFY('revoked_access_tokens', JSON_BUILD_OBJECT('jti', NEW.jti, 'expires_at', NEW.expires_at)::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS notify_access_token_revoked ON public."revoked_access_token";
+CREATE TRIGGER notify_access_token_revoked
+    AFTER INSERT
+    ON public."revoked_access_token"
+    FOR EACH ROW
+EXECUTE PROCEDURE notify_access_token_revoked();
@@ -0,0 +1,3 @@
+ALTER TABLE public."banner_delete_job"
+    DROP COLUMN IF EXISTS lease_until,
+    DROP COLUMN IF EXISTS locked_by;
@@ -0,0 +1,5 @@
+-- running job is held by one worker (locked_by) until lease_until, worker renews lease on every batch.
+-- Job of worker which died is taken by another one after its lease expires
+ALTER TABLE public."banner_delete_job"
+    ADD COLUMN IF NOT EXISTS locked_by   TEXT,
+    ADD COLUMN IF NOT EXISTS lease_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+ALTER TABLE public."banner_delete_job"
+    DROP COLUMN IF EXISTS attempts;
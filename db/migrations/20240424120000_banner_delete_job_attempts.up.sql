@@ -0,0 +1,4 @@
+-- failed attempts of running job. Job failed with transient error is released and retried
+-- after delay, it fails for good when attempts reach the limit of worker
+ALTER TABLE public."banner_delete_job"
+    ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
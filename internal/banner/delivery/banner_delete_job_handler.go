@@ -0,0 +1,111 @@
+package delivery
+
+import (
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	"github.com/SanExpett/banners-backend/pkg/utils"
+	"net/http"
+)
+
+// AddBannerDeleteJobHandler godoc
+//
+//	@Summary    delete banners by feature or tag
+//	@Description  record a job that deletes all banners of feature or of tag together with their tags
+//	@Description  and versions. Exactly one of feature_id and tag_id must be set. Response contains id of job,
+//	@Description  banners are deleted in background, progress is in /banner/delete_job.
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags BannerDeleteJob
+//	@Accept      json
+//	@Produce    json
+//	@Param      feature_id  query uint64 false  "feature id"
+//	@Param      tag_id  query uint64 false  "tag id"
+//	@Param      token  header string true  "token with banner:delete permission on feature_id, or on all features for tag_id"
+//	@Success    200  {object} delivery.ResponseID
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/delete_by [post]
+func (b *BannerHandler) AddBannerDeleteJobHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	var featureID, tagID uint64
+
+	if r.URL.Query().Has("feature_id") {
+		featureID, err = utils.ParseUint64FromRequest(r, "feature_id")
+		if err != nil {
+			delivery.HandleErr(w, r, b.logger, err)
+
+			return
+		}
+	}
+
+	if r.URL.Query().Has("tag_id") {
+		tagID, err = utils.ParseUint64FromRequest(r, "tag_id")
+		if err != nil {
+			delivery.HandleErr(w, r, b.logger, err)
+
+			return
+		}
+	}
+
+	jobID, err := b.service.AddBannerDeleteJob(ctx, featureID, tagID, principal)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, b.logger, delivery.NewResponseID(jobID))
+	b.logger.Infof("in AddBannerDeleteJobHandler: added job id=%d feature_id=%d tag_id=%d", jobID, featureID, tagID)
+}
+
+// GetBannerDeleteJobHandler godoc
+//
+//	@Summary    get banner delete job
+//	@Description  get status and count of deleted banners of job.
+//	@Description  Status can be pending, running, done or failed.
+//	@Tags BannerDeleteJob
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "job id"
+//	@Param      token  header string true  "token with banner:delete permission on feature of job, or on all features"
+//	@Success    200  {object} BannerDeleteJobResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/delete_job [get]
+func (b *BannerHandler) GetBannerDeleteJobHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	jobID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	job, err := b.service.GetBannerDeleteJob(ctx, jobID, principal)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, b.logger, NewBannerDeleteJobResponse(delivery.StatusResponseSuccessful, job))
+	b.logger.Infof("in GetBannerDeleteJobHandler: get job: %+v", job)
+}
@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/SanExpett/banners-backend/internal/banner/usecases"
 	"github.com/SanExpett/banners-backend/internal/server/delivery"
 	"github.com/SanExpett/banners-backend/pkg/models"
@@ -10,18 +11,36 @@ import (
 	"go.uber.org/zap"
 	"io"
 	"net/http"
-	"strconv"
 )
 
 var _ IBannerService = (*usecases.BannerService)(nil)
 
 type IBannerService interface {
-	AddBanner(ctx context.Context, r io.Reader, userID uint64) (uint64, error)
-	GetBanner(ctx context.Context, bannerID uint64, isAdmin bool) (*models.Content, error)
+	AddBanner(ctx context.Context, r io.Reader, principal *models.Principal) (uint64, error)
+	GetBanner(ctx context.Context, bannerID uint64, isAdmin bool, useLastRevision bool) (json.RawMessage, uint64, error)
+	GetUserBanner(ctx context.Context, featureID uint64, tagID uint64, isAdmin bool,
+		useLastRevision bool) (json.RawMessage, error)
 	GetBannersList(ctx context.Context, featureID uint64, tagID uint64, limit uint64,
-		offset uint64) ([]*models.Banner, error)
-	UpdateBanner(ctx context.Context, r io.Reader, bannerID uint64, userID uint64) error
-	DeleteBanner(ctx context.Context, bannerID uint64, userID uint64) error
+		offset uint64, principal *models.Principal) ([]*models.Banner, error)
+	UpdateBanner(ctx context.Context, r io.Reader, bannerID uint64, principal *models.Principal,
+		expectedRevision uint64) (uint64, error)
+	DeleteBanner(ctx context.Context, bannerID uint64, principal *models.Principal) error
+	TransferBanner(ctx context.Context, bannerID uint64, authorID uint64, principal *models.Principal,
+		expectedRevision uint64) (uint64, error)
+	AddFeatureSchema(ctx context.Context, featureID uint64, r io.Reader, userID uint64) (uint64, error)
+	GetFeatureSchema(ctx context.Context, featureID uint64, version uint64) (*models.FeatureSchema, error)
+	GetFeatureSchemasList(ctx context.Context, featureID uint64) ([]*models.FeatureSchema, error)
+	CheckFeatureSchema(ctx context.Context, featureID uint64, r io.Reader) ([]models.BannerSchemaViolation, error)
+	GetCacheStats() models.BannerCacheStats
+	GetBannerVersions(ctx context.Context, bannerID uint64,
+		principal *models.Principal) ([]*models.BannerVersion, error)
+	GetBannerVersion(ctx context.Context, bannerID uint64, version uint64,
+		principal *models.Principal) (*models.BannerVersion, error)
+	RestoreBannerVersion(ctx context.Context, bannerID uint64, version uint64, principal *models.Principal,
+		expectedRevision uint64) (uint64, error)
+	AddBannerDeleteJob(ctx context.Context, featureID uint64, tagID uint64,
+		principal *models.Principal) (uint64, error)
+	GetBannerDeleteJob(ctx context.Context, jobID uint64, principal *models.Principal) (*models.BannerDeleteJob, error)
 }
 
 type BannerHandler struct {
@@ -46,51 +65,34 @@ func NewBannerHandler(bannerService IBannerService) (*BannerHandler, error) {
 //	@Summary    add banner
 //	@Description  add Banner by data
 //	@Description Error.status can be:
-//	@Description StatusErrBadRequest      = 400
+//	@Description StatusErrBadRequest      = 400 (body.details lists missing feature_ids and tag_ids
+//	@Description  or content errors if content does not satisfy schema of feature)
+//	@Description StatusErrConflict        = 409 (body.details lists taken feature and tag pairs)
 //	@Description  StatusErrInternalServer  = 500
 //	@Tags Banner
 //
 //	@Accept      json
 //	@Produce    json
 //	@Param      banner  body models.PreBanner true  "Banner data for adding"
-//	@Param      token  header string true  "admin token"
+//	@Param      token  header string true  "token with banner:write permission on feature of banner"
 //	@Success    200  {object} delivery.ResponseID
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
 //	@Router      /banner/add [post]
 func (b *BannerHandler) AddBannerHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
-
-		return
-	}
-
 	ctx := r.Context()
 
-	isAdmin, err := delivery.GetIsAdminFromHeader(r)
-	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
-
-		return
-	}
-
-	if !isAdmin {
-		delivery.HandleErr(w, b.logger, delivery.ErrNotAdmin)
-
-		return
-	}
-
-	userID, err := delivery.GetUserIDFromHeader(r)
+	principal, err := delivery.GetPrincipal(r)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	bannerID, err := b.service.AddBanner(ctx, r.Body, userID)
+	bannerID, err := b.service.AddBanner(ctx, r.Body, principal)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
@@ -107,101 +109,139 @@ func (b *BannerHandler) AddBannerHandler(w http.ResponseWriter, r *http.Request)
 //	@Accept      json
 //	@Produce    json
 //	@Param      id  query uint64 true  "banner id"
+//	@Param      use_last_revision  query bool false  "get the latest banner revision bypassing cache"
 //	@Param      token  header string true  "user token"
 //	@Success    200  {object} BannerResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
 //	@Router      /banner/get [get]
 func (b *BannerHandler) GetBannerHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
-
-		return
-	}
-
 	ctx := r.Context()
 
-	isAdmin, err := delivery.GetIsAdminFromHeader(r)
+	principal, err := delivery.GetPrincipal(r)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
 	bannerID, err := utils.ParseUint64FromRequest(r, "id")
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	banner, err := b.service.GetBanner(ctx, bannerID, isAdmin)
+	useLastRevision := utils.ParseBoolFromRequest(r, "use_last_revision")
+
+	// inactive banners are shown only to those who can read banners of all features,
+	// feature of banner is not known until it is found
+	banner, revision, err := b.service.GetBanner(ctx, bannerID, principal.CanEverywhere(models.PermBannerRead),
+		useLastRevision)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
+	delivery.SetETag(w, revision)
 	delivery.SendOkResponse(w, b.logger, NewBannerResponse(delivery.StatusResponseSuccessful, banner))
-	b.logger.Infof("in GetBannerHandler: get Banner: %+v", banner)
+	b.logger.Infof("in GetBannerHandler: get Banner: %s", banner)
 }
 
-// DeleteBannerHandler godoc
+// GetUserBannerHandler godoc
 //
-//	@Summary     delete banner
-//	@Description  delete banner for author using user id from header\jwt.
-//	@Description  This totally removed banner. Recovery will be impossible
+//	@Summary    get user banner
+//	@Description  get banner for user by feature id and tag id
 //	@Tags Banner
 //	@Accept      json
 //	@Produce    json
-//	@Param      id  path uint64 true  "banner id"
-//	@Param      token  header string true  "admin token"
-//	@Success    200  {object} delivery.Response
+//	@Param      feature_id  query uint64 true  "feature id"
+//	@Param      tag_id  query uint64 true  "tag id"
+//	@Param      use_last_revision  query bool false  "get the latest banner revision bypassing cache"
+//	@Param      token  header string true  "user token"
+//	@Success    200  {object} BannerResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
-//	@Router      /banner/delete [delete]
-func (b *BannerHandler) DeleteBannerHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /user_banner [get]
+func (b *BannerHandler) GetUserBannerHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	ctx := r.Context()
+	featureID, err := utils.ParseUint64FromRequest(r, "feature_id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
 
-	isAdmin, err := delivery.GetIsAdminFromHeader(r)
+		return
+	}
+
+	tagID, err := utils.ParseUint64FromRequest(r, "tag_id")
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	if !isAdmin {
-		delivery.HandleErr(w, b.logger, delivery.ErrNotAdmin)
+	useLastRevision := utils.ParseBoolFromRequest(r, "use_last_revision")
+
+	banner, err := b.service.GetUserBanner(ctx, featureID, tagID, principal.Can(models.PermBannerRead, featureID),
+		useLastRevision)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	userID, err := delivery.GetUserIDFromHeader(r)
+	delivery.SendOkResponse(w, b.logger, NewBannerResponse(delivery.StatusResponseSuccessful, banner))
+	b.logger.Infof("in GetUserBannerHandler: get Banner: %s", banner)
+}
+
+// DeleteBannerHandler godoc
+//
+//	@Summary     delete banner
+//	@Description  delete banner by anyone with banner:delete permission on its feature, not only by its author.
+//	@Description  This totally removed banner. Recovery will be impossible
+//	@Description Error.status can be:
+//	@Description StatusErrForbidden       = 403 (no banner:delete permission on feature of banner)
+//	@Description StatusErrNotFound        = 404 (banner does not exist)
+//	@Tags Banner
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  path uint64 true  "banner id"
+//	@Param      token  header string true  "token with banner:delete permission on feature of banner"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/{id} [delete]
+func (b *BannerHandler) DeleteBannerHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	bannerIDStr := delivery.GetPathParam(r.URL.Path)
-	bannerID, err := strconv.ParseUint(bannerIDStr, 10, 64)
+	bannerID, err := delivery.GetPathParamUint64(r, "id")
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	err = b.service.DeleteBanner(ctx, bannerID, userID)
+	err = b.service.DeleteBanner(ctx, bannerID, principal)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
@@ -214,69 +254,132 @@ func (b *BannerHandler) DeleteBannerHandler(w http.ResponseWriter, r *http.Reque
 // UpdateBannerHandler godoc
 //
 //	@Summary    update banner
-//	@Description  update banner by data
+//	@Description  partially update banner: fields omitted in body keep their current values,
+//	@Description  tag_ids, if set, replace the whole tag set of banner. If-Match must contain revision
+//	@Description  of banner the change is based on, new revision is returned in ETag header.
 //	@Description Error.status can be:
-//	@Description StatusErrBadRequest      = 400
+//	@Description StatusErrBadRequest      = 400 (body.details lists missing feature_ids and tag_ids
+//	@Description  or content errors if content does not satisfy schema of feature)
+//	@Description StatusErrConflict        = 409 (body.details lists taken feature and tag pairs
+//	@Description  or banner_id and current_revision if banner was changed since If-Match revision)
 //	@Description  StatusErrInternalServer  = 500
 //	@Tags Banner
 //
 //	@Accept      json
 //	@Produce    json
-//	@Param      token  header string true  "admin token"
-//	@Param      Banner  body models.PreBanner true  "banner data for updating"
+//	@Param      token  header string true  "token with banner:write permission on feature of banner"
+//	@Param      If-Match  header string true  "banner revision, e.g. \"3\""
+//	@Param      Banner  body models.BannerPatch true  "banner fields for updating"
 //	@Param      id  path uint64 true  "banner id"
 //	@Success    200  {object} delivery.ResponseID
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
-//	@Router      /banner/update [patch]
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/{id} [patch]
 func (b *BannerHandler) UpdateBannerHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPatch {
-		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
+	bannerID, err := delivery.GetPathParamUint64(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	expectedRevision, err := delivery.GetRevisionFromIfMatch(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	revision, err := b.service.UpdateBanner(ctx, r.Body, bannerID, principal, expectedRevision)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SetETag(w, revision)
+	delivery.SendOkResponse(w, b.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulUpdateBanner))
+	b.logger.Infof("in UpdateBannerHandler: updated banner id=%d revision=%d", bannerID, revision)
+}
+
+// TransferBannerHandler godoc
+//
+//	@Summary    transfer banner
+//	@Description  make another user author of banner. Author is kept only for attribution,
+//	@Description  rights to edit and delete banner are given by roles. If-Match must contain revision
+//	@Description  of banner the transfer is based on, new revision is returned in ETag header.
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description StatusErrForbidden       = 403 (no banner:write permission on feature of banner)
+//	@Description StatusErrNotFound        = 404 (banner or new author does not exist)
+//	@Description StatusErrConflict        = 409 (body.details has banner_id and current_revision
+//	@Description  if banner was changed since If-Match revision)
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags Banner
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "banner id"
+//	@Param      author_id  query uint64 true  "id of new author"
+//	@Param      token  header string true  "token with banner:write permission on feature of banner"
+//	@Param      If-Match  header string true  "banner revision, e.g. \"3\""
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/transfer [post]
+func (b *BannerHandler) TransferBannerHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	isAdmin, err := delivery.GetIsAdminFromHeader(r)
+	principal, err := delivery.GetPrincipal(r)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	if !isAdmin {
-		delivery.HandleErr(w, b.logger, delivery.ErrNotAdmin)
+	bannerID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	userID, err := delivery.GetUserIDFromHeader(r)
+	authorID, err := utils.ParseUint64FromRequest(r, "author_id")
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	bannerIDStr := delivery.GetPathParam(r.URL.Path)
-	bannerID, err := strconv.ParseUint(bannerIDStr, 10, 64)
+	expectedRevision, err := delivery.GetRevisionFromIfMatch(r)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
-	err = b.service.UpdateBanner(ctx, r.Body, bannerID, userID)
+	revision, err := b.service.TransferBanner(ctx, bannerID, authorID, principal, expectedRevision)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
 
+	delivery.SetETag(w, revision)
 	delivery.SendOkResponse(w, b.logger,
-		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulUpdateBanner))
-	b.logger.Infof("in UpdateBannerHandler: added banner id= %+v", bannerID)
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulTransferBanner))
+	b.logger.Infof("in TransferBannerHandler: banner id=%d is transferred to user id=%d", bannerID, authorID)
 }
 
 // GetBannersListHandler godoc
@@ -290,30 +393,18 @@ func (b *BannerHandler) UpdateBannerHandler(w http.ResponseWriter, r *http.Reque
 //	@Param      tag_id  query uint64 false  "tag_id"
 //	@Param      limit  query uint64 false  "limit Banners"
 //	@Param      offset  query uint64 false  "offset of Banners"
-//	@Param      token  header string true  "admin token"
+//	@Param      token  header string true  "token with banner:read permission on feature_id, or on all features without it"
 //	@Success    200  {object} BannerListResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
 //	@Router      /banner/get_list [get]
 func (b *BannerHandler) GetBannersListHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
-
-		return
-	}
-
 	ctx := r.Context()
 
-	isAdmin, err := delivery.GetIsAdminFromHeader(r)
+	principal, err := delivery.GetPrincipal(r)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
-
-		return
-	}
-
-	if !isAdmin {
-		delivery.HandleErr(w, b.logger, delivery.ErrNotAdmin)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
@@ -338,9 +429,9 @@ func (b *BannerHandler) GetBannersListHandler(w http.ResponseWriter, r *http.Req
 		tagID = 0
 	}
 
-	banners, err := b.service.GetBannersList(ctx, featureID, tagID, limit, offset)
+	banners, err := b.service.GetBannersList(ctx, featureID, tagID, limit, offset, principal)
 	if err != nil {
-		delivery.HandleErr(w, b.logger, err)
+		delivery.HandleErr(w, r, b.logger, err)
 
 		return
 	}
@@ -348,3 +439,23 @@ func (b *BannerHandler) GetBannersListHandler(w http.ResponseWriter, r *http.Req
 	delivery.SendOkResponse(w, b.logger, NewBannerListResponse(delivery.StatusResponseSuccessful, banners))
 	b.logger.Infof("in GetBannerListHandler: get Banner list: %+v", banners)
 }
+
+// GetBannerCacheStatsHandler godoc
+//
+//	@Summary    get banner cache stats
+//	@Description  get mode, size and last build time of banners cache, it is used to alert when cache falls behind
+//	@Tags Banner
+//	@Accept      json
+//	@Produce    json
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} BannerCacheStatsResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/cache_stats [get]
+func (b *BannerHandler) GetBannerCacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats := b.service.GetCacheStats()
+
+	delivery.SendOkResponse(w, b.logger, NewBannerCacheStatsResponse(delivery.StatusResponseSuccessful, stats))
+	b.logger.Infof("in GetBannerCacheStatsHandler: get stats: %+v", stats)
+}
@@ -0,0 +1,169 @@
+package delivery
+
+import (
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	"github.com/SanExpett/banners-backend/pkg/utils"
+	"net/http"
+)
+
+// GetBannerVersionsHandler godoc
+//
+//	@Summary    get banner versions
+//	@Description  get retained versions of banner, the latest first
+//	@Tags BannerVersion
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "banner id"
+//	@Param      token  header string true  "token with banner:read permission on feature of banner"
+//	@Success    200  {object} BannerVersionListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/versions [get]
+func (b *BannerHandler) GetBannerVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	bannerID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	bannerVersions, err := b.service.GetBannerVersions(ctx, bannerID, principal)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, b.logger,
+		NewBannerVersionListResponse(delivery.StatusResponseSuccessful, bannerVersions))
+	b.logger.Infof("in GetBannerVersionsHandler: get %d versions of banner id=%d", len(bannerVersions), bannerID)
+}
+
+// GetBannerVersionHandler godoc
+//
+//	@Summary    get banner version
+//	@Description  get given version of banner
+//	@Tags BannerVersion
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "banner id"
+//	@Param      version  query uint64 true  "banner version"
+//	@Param      token  header string true  "token with banner:read permission on feature of banner"
+//	@Success    200  {object} BannerVersionResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/version [get]
+func (b *BannerHandler) GetBannerVersionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	bannerID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	version, err := utils.ParseUint64FromRequest(r, "version")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	bannerVersion, err := b.service.GetBannerVersion(ctx, bannerID, version, principal)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, b.logger, NewBannerVersionResponse(delivery.StatusResponseSuccessful, bannerVersion))
+	b.logger.Infof("in GetBannerVersionHandler: get version: %+v", bannerVersion)
+}
+
+// RestoreBannerVersionHandler godoc
+//
+//	@Summary    restore banner version
+//	@Description  make feature, tags, content and activity of given version current.
+//	@Description  Restored state is saved as a new version. Restoring is an update of banner, so If-Match
+//	@Description  must contain revision of banner and banner is restored only if it is still at this revision.
+//	@Description  New revision is returned in ETag header.
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400 (no If-Match, body.details lists missing feature_ids and tag_ids
+//	@Description  or content errors if content does not satisfy schema of feature)
+//	@Description StatusErrConflict        = 409 (body.details lists taken feature and tag pairs
+//	@Description  or banner_id and current_revision if banner was changed since If-Match revision)
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags BannerVersion
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "banner id"
+//	@Param      version  query uint64 true  "banner version"
+//	@Param      token  header string true  "token with banner:write permission on feature of banner"
+//	@Param      If-Match  header string true  "banner revision, e.g. \"3\""
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /banner/restore [post]
+func (b *BannerHandler) RestoreBannerVersionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	bannerID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	version, err := utils.ParseUint64FromRequest(r, "version")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	expectedRevision, err := delivery.GetRevisionFromIfMatch(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	revision, err := b.service.RestoreBannerVersion(ctx, bannerID, version, principal, expectedRevision)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SetETag(w, revision)
+	delivery.SendOkResponse(w, b.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulRestoreBanner))
+	b.logger.Infof("in RestoreBannerVersionHandler: restored banner id=%d version=%d", bannerID, version)
+}
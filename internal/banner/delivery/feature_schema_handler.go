@@ -0,0 +1,171 @@
+package delivery
+
+import (
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	"github.com/SanExpett/banners-backend/pkg/utils"
+	"net/http"
+)
+
+// AddFeatureSchemaHandler godoc
+//
+//	@Summary    add feature schema
+//	@Description  save JSON Schema as the next version of feature content schema.
+//	@Description  Content of added and updated banners of feature is validated against the latest version.
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags FeatureSchema
+//
+//	@Accept      json
+//	@Produce    json
+//	@Param      feature_id  query uint64 true  "feature id"
+//	@Param      schema  body object true  "JSON Schema of banner content"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} FeatureSchemaVersionResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature_schema/add [post]
+func (b *BannerHandler) AddFeatureSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	featureID, err := utils.ParseUint64FromRequest(r, "feature_id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	version, err := b.service.AddFeatureSchema(ctx, featureID, r.Body, principal.UserID)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, b.logger, NewFeatureSchemaVersionResponse(delivery.StatusResponseSuccessful, version))
+	b.logger.Infof("in AddFeatureSchemaHandler: added schema featureID=%d version=%d", featureID, version)
+}
+
+// GetFeatureSchemaHandler godoc
+//
+//	@Summary    get feature schema
+//	@Description  get given version of feature content schema, the latest one if version is omitted
+//	@Tags FeatureSchema
+//	@Accept      json
+//	@Produce    json
+//	@Param      feature_id  query uint64 true  "feature id"
+//	@Param      version  query uint64 false  "schema version"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} FeatureSchemaResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature_schema/get [get]
+func (b *BannerHandler) GetFeatureSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	featureID, err := utils.ParseUint64FromRequest(r, "feature_id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	version, err := utils.ParseUint64FromRequest(r, "version")
+	if err != nil {
+		version = 0
+	}
+
+	featureSchema, err := b.service.GetFeatureSchema(ctx, featureID, version)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, b.logger, NewFeatureSchemaResponse(delivery.StatusResponseSuccessful, featureSchema))
+	b.logger.Infof("in GetFeatureSchemaHandler: get schema: %+v", featureSchema)
+}
+
+// GetFeatureSchemasListHandler godoc
+//
+//	@Summary    get feature schemas list
+//	@Description  get all versions of feature content schema, the latest first
+//	@Tags FeatureSchema
+//	@Accept      json
+//	@Produce    json
+//	@Param      feature_id  query uint64 true  "feature id"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} FeatureSchemaListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature_schema/get_list [get]
+func (b *BannerHandler) GetFeatureSchemasListHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	featureID, err := utils.ParseUint64FromRequest(r, "feature_id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	featureSchemas, err := b.service.GetFeatureSchemasList(ctx, featureID)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, b.logger,
+		NewFeatureSchemaListResponse(delivery.StatusResponseSuccessful, featureSchemas))
+	b.logger.Infof("in GetFeatureSchemasListHandler: get %d schemas of featureID=%d",
+		len(featureSchemas), featureID)
+}
+
+// CheckFeatureSchemaHandler godoc
+//
+//	@Summary    check feature schema
+//	@Description  report existing banners of feature which content would fail the proposed schema.
+//	@Description  The schema is not saved.
+//	@Tags FeatureSchema
+//	@Accept      json
+//	@Produce    json
+//	@Param      feature_id  query uint64 true  "feature id"
+//	@Param      schema  body object true  "proposed JSON Schema of banner content"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} SchemaViolationsResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature_schema/check [post]
+func (b *BannerHandler) CheckFeatureSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	featureID, err := utils.ParseUint64FromRequest(r, "feature_id")
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	violations, err := b.service.CheckFeatureSchema(ctx, featureID, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, b.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, b.logger, NewSchemaViolationsResponse(delivery.StatusResponseSuccessful, violations))
+	b.logger.Infof("in CheckFeatureSchemaHandler: %d banners of featureID=%d fail schema",
+		len(violations), featureID)
+}
@@ -1,18 +1,23 @@
 package delivery
 
-import "github.com/SanExpett/banners-backend/pkg/models"
+import (
+	"encoding/json"
+	"github.com/SanExpett/banners-backend/pkg/models"
+)
 
 const (
-	ResponseSuccessfulDeleteBanner = "Баннер успешно удален"
-	ResponseSuccessfulUpdateBanner = "Баннер успешно обновлен"
+	ResponseSuccessfulDeleteBanner   = "Баннер успешно удален"
+	ResponseSuccessfulUpdateBanner   = "Баннер успешно обновлен"
+	ResponseSuccessfulRestoreBanner  = "Версия баннера успешно восстановлена"
+	ResponseSuccessfulTransferBanner = "Автор баннера успешно изменен"
 )
 
 type BannerResponse struct {
 	Status int             `json:"status"`
-	Body   *models.Content `json:"body"`
+	Body   json.RawMessage `json:"body" swaggertype:"object"`
 }
 
-func NewBannerResponse(status int, body *models.Content) *BannerResponse {
+func NewBannerResponse(status int, body json.RawMessage) *BannerResponse {
 	return &BannerResponse{
 		Status: status,
 		Body:   body,
@@ -30,3 +35,103 @@ func NewBannerListResponse(status int, body []*models.Banner) *BannerListRespons
 		Body:   body,
 	}
 }
+
+type FeatureSchemaVersionBody struct {
+	Version uint64 `json:"version"`
+}
+
+type FeatureSchemaVersionResponse struct {
+	Status int                      `json:"status"`
+	Body   FeatureSchemaVersionBody `json:"body"`
+}
+
+func NewFeatureSchemaVersionResponse(status int, version uint64) *FeatureSchemaVersionResponse {
+	return &FeatureSchemaVersionResponse{
+		Status: status,
+		Body:   FeatureSchemaVersionBody{Version: version},
+	}
+}
+
+type FeatureSchemaResponse struct {
+	Status int                   `json:"status"`
+	Body   *models.FeatureSchema `json:"body"`
+}
+
+func NewFeatureSchemaResponse(status int, body *models.FeatureSchema) *FeatureSchemaResponse {
+	return &FeatureSchemaResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type FeatureSchemaListResponse struct {
+	Status int                     `json:"status"`
+	Body   []*models.FeatureSchema `json:"body"`
+}
+
+func NewFeatureSchemaListResponse(status int, body []*models.FeatureSchema) *FeatureSchemaListResponse {
+	return &FeatureSchemaListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type SchemaViolationsResponse struct {
+	Status int                            `json:"status"`
+	Body   []models.BannerSchemaViolation `json:"body"`
+}
+
+func NewSchemaViolationsResponse(status int, body []models.BannerSchemaViolation) *SchemaViolationsResponse {
+	return &SchemaViolationsResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type BannerCacheStatsResponse struct {
+	Status int                     `json:"status"`
+	Body   models.BannerCacheStats `json:"body"`
+}
+
+func NewBannerCacheStatsResponse(status int, body models.BannerCacheStats) *BannerCacheStatsResponse {
+	return &BannerCacheStatsResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type BannerVersionResponse struct {
+	Status int                   `json:"status"`
+	Body   *models.BannerVersion `json:"body"`
+}
+
+func NewBannerVersionResponse(status int, body *models.BannerVersion) *BannerVersionResponse {
+	return &BannerVersionResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type BannerVersionListResponse struct {
+	Status int                     `json:"status"`
+	Body   []*models.BannerVersion `json:"body"`
+}
+
+func NewBannerVersionListResponse(status int, body []*models.BannerVersion) *BannerVersionListResponse {
+	return &BannerVersionListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type BannerDeleteJobResponse struct {
+	Status int                     `json:"status"`
+	Body   *models.BannerDeleteJob `json:"body"`
+}
+
+func NewBannerDeleteJobResponse(status int, body *models.BannerDeleteJob) *BannerDeleteJobResponse {
+	return &BannerDeleteJobResponse{
+		Status: status,
+		Body:   body,
+	}
+}
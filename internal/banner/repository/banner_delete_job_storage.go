@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/internal/server/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrBannerDeleteJobNotFound = myerrors.NewNotFoundError("banner_delete_job_not_found", myerrors.Message{
+		RU: "Эта задача удаления баннеров не найдена",
+		EN: "Banner delete job is not found",
+	})
+
+	ErrBannerDeleteJobLeaseLost   = errors.New("lease of banner delete job is lost, job is taken by another worker")
+	ErrBannerDeleteBatchTransient = errors.New("banner delete batch failed with transient error, " +
+		"it can be retried")
+)
+
+const selectBannerDeleteJobColumns = `id, author_id, COALESCE(feature_id, 0), COALESCE(tag_id, 0), status,
+	deleted_count, attempts, error, created_at, updated_at`
+
+func (b *BannerStorage) scanBannerDeleteJob(row pgx.Row) (*models.BannerDeleteJob, error) {
+	job := &models.BannerDeleteJob{} //nolint:exhaustruct
+
+	err := row.Scan(&job.ID, &job.AuthorID, &job.FeatureID, &job.TagID, &job.Status,
+		&job.DeletedCount, &job.Attempts, &job.Error, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return job, nil
+}
+
+// AddBannerDeleteJob records a job that deletes all banners of feature or of tag, 0 means not set.
+func (b *BannerStorage) AddBannerDeleteJob(ctx context.Context, featureID uint64, tagID uint64,
+	userID uint64) (uint64, error) {
+	SQLAddBannerDeleteJob := `INSERT INTO public."banner_delete_job" (author_id, feature_id, tag_id)
+		VALUES ($1, NULLIF($2::BIGINT, 0), NULLIF($3::BIGINT, 0))
+		RETURNING id`
+
+	var jobID uint64
+
+	jobRow := b.pool.QueryRow(ctx, SQLAddBannerDeleteJob, userID, featureID, tagID)
+	if err := jobRow.Scan(&jobID); err != nil {
+		b.logger.Errorf("in AddBannerDeleteJob: featureID=%d tagID=%d err=%+v", featureID, tagID, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return jobID, nil
+}
+
+func (b *BannerStorage) GetBannerDeleteJob(ctx context.Context, jobID uint64) (*models.BannerDeleteJob, error) {
+	SQLSelectBannerDeleteJob := `SELECT ` + selectBannerDeleteJobColumns + `
+		FROM public."banner_delete_job"
+		WHERE id = $1`
+
+	job, err := b.scanBannerDeleteJob(b.pool.QueryRow(ctx, SQLSelectBannerDeleteJob, jobID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrBannerDeleteJobNotFound)
+		}
+
+		b.logger.Errorf("error with jobID=%d: %+v", jobID, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return job, nil
+}
+
+// TakeBannerDeleteJob marks the oldest unfinished job as running by worker for lease and returns it,
+// or nil if there is none. Running job is taken only when its lease has expired, so a job of worker
+// which died is continued by another one, but a job is never run by two workers at once.
+func (b *BannerStorage) TakeBannerDeleteJob(ctx context.Context, workerID string,
+	lease time.Duration) (*models.BannerDeleteJob, error) {
+	SQLTakeBannerDeleteJob := `UPDATE public."banner_delete_job"
+		SET status = $1, locked_by = $3, lease_until = NOW() + $4::DOUBLE PRECISION * INTERVAL '1 second'
+		WHERE id = (SELECT id FROM public."banner_delete_job"
+			WHERE status = $2 OR (status = $1 AND (lease_until IS NULL OR lease_until < NOW()))
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING ` + selectBannerDeleteJobColumns
+
+	job, err := b.scanBannerDeleteJob(b.pool.QueryRow(ctx, SQLTakeBannerDeleteJob,
+		models.BannerDeleteJobRunning, models.BannerDeleteJobPending, workerID, lease.Seconds()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil //nolint:nilnil
+		}
+
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return job, nil
+}
+
+// DeleteBannersBatch deletes at most batchSize banners of job together with their tags
+// and versions, counts them in job and returns how many were deleted and whether banners of job
+// are left. Banners locked by other transactions are skipped, but they are still left, so job
+// is not done until they are deleted too. Lease of worker is renewed, if it is lost,
+// ErrBannerDeleteJobLeaseLost is returned and nothing is deleted. Errors after which batch can succeed
+// if it is run again are wrapped with ErrBannerDeleteBatchTransient.
+func (b *BannerStorage) DeleteBannersBatch(ctx context.Context, job *models.BannerDeleteJob, workerID string,
+	lease time.Duration, batchSize uint64) (uint64, bool, error) {
+	SQLRenewLease := `UPDATE public."banner_delete_job"
+		SET lease_until = NOW() + $3::DOUBLE PRECISION * INTERVAL '1 second'
+		WHERE id = $1 AND status = $4 AND locked_by = $2`
+
+	SQLDeleteBannersBatch := `DELETE FROM public."banner"
+		WHERE id IN (SELECT b.id FROM public."banner" b
+			WHERE ($1::BIGINT = 0 OR b.feature_id = $1)
+				AND ($2::BIGINT = 0 OR EXISTS (SELECT 1 FROM public."banner_tag" bt
+					WHERE bt.banner_id = b.id AND bt.tag_id = $2))
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED)`
+
+	SQLCountDeletedBanners := `UPDATE public."banner_delete_job" SET deleted_count = deleted_count + $1
+		WHERE id = $2`
+
+	SQLBannersLeft := `SELECT EXISTS (SELECT 1 FROM public."banner" b
+		WHERE ($1::BIGINT = 0 OR b.feature_id = $1)
+			AND ($2::BIGINT = 0 OR EXISTS (SELECT 1 FROM public."banner_tag" bt
+				WHERE bt.banner_id = b.id AND bt.tag_id = $2)))`
+
+	var (
+		deletedCount uint64
+		bannersLeft  bool
+	)
+
+	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, SQLRenewLease, job.ID, workerID, lease.Seconds(), models.BannerDeleteJobRunning)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrBannerDeleteJobLeaseLost
+		}
+
+		result, err = tx.Exec(ctx, SQLDeleteBannersBatch, job.FeatureID, job.TagID, batchSize)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		deletedCount = uint64(result.RowsAffected())
+
+		if _, err = tx.Exec(ctx, SQLCountDeletedBanners, deletedCount, job.ID); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return tx.QueryRow(ctx, SQLBannersLeft, job.FeatureID, job.TagID).Scan(&bannersLeft) //nolint:wrapcheck
+	})
+	if err != nil {
+		b.logger.Errorf("in DeleteBannersBatch: job=%+v err=%+v", job, err)
+
+		if repository.IsTransientPgErr(err) {
+			return 0, false, fmt.Errorf("%w: %w", ErrBannerDeleteBatchTransient, err)
+		}
+
+		return 0, false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return deletedCount, bannersLeft, nil
+}
+
+// FinishBannerDeleteJob marks job held by worker as done, or as failed with errMessage if it is not empty.
+// If lease of worker is lost, ErrBannerDeleteJobLeaseLost is returned and job is left to its new worker.
+func (b *BannerStorage) FinishBannerDeleteJob(ctx context.Context, jobID uint64, workerID string,
+	errMessage string) error {
+	SQLFinishBannerDeleteJob := `UPDATE public."banner_delete_job"
+		SET status = $1, error = $2, locked_by = NULL, lease_until = NULL,
+			attempts = attempts + CASE WHEN $2::TEXT = '' THEN 0 ELSE 1 END
+		WHERE id = $3 AND status = $4 AND locked_by = $5`
+
+	status := models.BannerDeleteJobDone
+	if errMessage != "" {
+		status = models.BannerDeleteJobFailed
+	}
+
+	result, err := b.pool.Exec(ctx, SQLFinishBannerDeleteJob, status, errMessage, jobID,
+		models.BannerDeleteJobRunning, workerID)
+	if err != nil {
+		b.logger.Errorf("in FinishBannerDeleteJob: jobID=%d err=%+v", jobID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrBannerDeleteJobLeaseLost)
+	}
+
+	return nil
+}
+
+// ReleaseBannerDeleteJob gives up job held by worker after failed attempt with errMessage, job stays
+// running and is taken again by any worker after retryAfter. If lease of worker is lost,
+// ErrBannerDeleteJobLeaseLost is returned.
+func (b *BannerStorage) ReleaseBannerDeleteJob(ctx context.Context, jobID uint64, workerID string,
+	errMessage string, retryAfter time.Duration) error {
+	SQLReleaseBannerDeleteJob := `UPDATE public."banner_delete_job"
+		SET attempts = attempts + 1, error = $1, locked_by = NULL,
+			lease_until = NOW() + $2::DOUBLE PRECISION * INTERVAL '1 second'
+		WHERE id = $3 AND status = $4 AND locked_by = $5`
+
+	result, err := b.pool.Exec(ctx, SQLReleaseBannerDeleteJob, errMessage, retryAfter.Seconds(), jobID,
+		models.BannerDeleteJobRunning, workerID)
+	if err != nil {
+		b.logger.Errorf("in ReleaseBannerDeleteJob: jobID=%d err=%+v", jobID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrBannerDeleteJobLeaseLost)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/banners-backend/internal/server/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"strings"
+)
+
+var (
+	MessageErrMissingFeature = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Не существует фича с id %v",
+		EN: "Feature with id %v does not exist",
+	}
+	MessageErrMissingTags = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Не существуют теги с id %v",
+		EN: "Tags with ids %v do not exist",
+	}
+)
+
+func (b *BannerStorage) selectMissingReferences(ctx context.Context, featureID uint64,
+	tagIDs []uint64) (*models.MissingReferences, error) {
+	SQLSelectMissingReferences :=
+		`SELECT NOT EXISTS (SELECT 1 FROM public."feature" WHERE id = $1),
+			ARRAY(SELECT t.id FROM UNNEST($2::BIGINT[]) AS t(id)
+				WHERE NOT EXISTS (SELECT 1 FROM public."tag" WHERE tag.id = t.id)
+				ORDER BY t.id)`
+
+	var featureMissing bool
+
+	missingReferences := &models.MissingReferences{} //nolint:exhaustruct
+
+	referencesRow := b.pool.QueryRow(ctx, SQLSelectMissingReferences, featureID, tagIDs)
+	if err := referencesRow.Scan(&featureMissing, &missingReferences.TagIDs); err != nil {
+		b.logger.Errorf("in selectMissingReferences: featureID=%d tagIDs=%v err=%+v", featureID, tagIDs, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if featureMissing {
+		missingReferences.FeatureIDs = []uint64{featureID}
+	}
+
+	return missingReferences, nil
+}
+
+// CheckBannerReferences returns ValidationError listing feature and tags which do not exist.
+func (b *BannerStorage) CheckBannerReferences(ctx context.Context, featureID uint64, tagIDs []uint64) error {
+	missingReferences, err := b.selectMissingReferences(ctx, featureID, tagIDs)
+	if err != nil {
+		return err
+	}
+
+	var messagesRU, messagesEN []string
+
+	if len(missingReferences.FeatureIDs) != 0 {
+		messagesRU = append(messagesRU, fmt.Sprintf(MessageErrMissingFeature.RU, missingReferences.FeatureIDs[0]))
+		messagesEN = append(messagesEN, fmt.Sprintf(MessageErrMissingFeature.EN, missingReferences.FeatureIDs[0]))
+	}
+
+	if len(missingReferences.TagIDs) != 0 {
+		messagesRU = append(messagesRU, fmt.Sprintf(MessageErrMissingTags.RU, missingReferences.TagIDs))
+		messagesEN = append(messagesEN, fmt.Sprintf(MessageErrMissingTags.EN, missingReferences.TagIDs))
+	}
+
+	if len(messagesRU) == 0 {
+		return nil
+	}
+
+	return myerrors.NewValidationError(missingReferences, "missing_references", myerrors.Message{
+		RU: strings.Join(messagesRU, ", "),
+		EN: strings.Join(messagesEN, ", "),
+	})
+}
+
+// handleMissingReferencesErr turns violation of foreign key, which happens when feature
+// or tag is deleted after CheckBannerReferences, into ValidationError.
+func (b *BannerStorage) handleMissingReferencesErr(ctx context.Context, err error, featureID uint64,
+	tagIDs []uint64) error {
+	if !repository.IsPgErr(err, repository.PgErrCodeForeignKeyViolation) {
+		return err
+	}
+
+	errCheck := b.CheckBannerReferences(ctx, featureID, tagIDs)
+	if errCheck != nil {
+		return errCheck
+	}
+
+	return err
+}
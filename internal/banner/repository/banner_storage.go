@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Masterminds/squirrel"
@@ -14,44 +15,92 @@ import (
 	"go.uber.org/zap"
 )
 
+// ChannelBannerChanges is notified by triggers on every change of banners.
+const ChannelBannerChanges = "banner_changes"
+
 var (
-	ErrBannerNotFound             = myerrors.NewError("Этот баннер не найден")
-	ErrNoAffectedBannerRows       = myerrors.NewError("Не получилось обновить данные баннера")
-	ErrNotAdminGetNotActiveBanner = myerrors.NewError("Только админ может получить неактивный баннер")
+	ErrBannerNotFound = myerrors.NewNotFoundError("banner_not_found", myerrors.Message{
+		RU: "Этот баннер не найден",
+		EN: "Banner is not found",
+	})
+	ErrNoAffectedBannerRows = myerrors.NewError("banner_not_updated", myerrors.Message{
+		RU: "Не получилось обновить данные баннера",
+		EN: "Banner could not be updated",
+	})
+	ErrNotAdminGetNotActiveBanner = myerrors.NewForbiddenError("banner_not_active", myerrors.Message{
+		RU: "Неактивный баннер можно получить только с правом banner:read",
+		EN: "Inactive banner can be got only with banner:read permission",
+	})
+	ErrBannerAuthorNotFound = myerrors.NewNotFoundError("user_not_found", myerrors.Message{
+		RU: "Новый автор баннера не найден",
+		EN: "New author of banner is not found",
+	})
+
+	MessageErrFeatureTagConflict = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Фича и тег должны однозначно определять баннер, но эти пары уже заняты другими баннерами",
+		EN: "Feature and tag must identify one banner, but these pairs are already taken by other banners",
+	}
 
-	NameSeqBanner = pgx.Identifier{"public", "banner_id_seq"} //nolint:gochecknoglobals
+	MessageErrRevisionConflict = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Баннер уже изменен, получите его текущую ревизию и повторите изменение",
+		EN: "Banner has been changed, get its current revision and repeat the change",
+	}
+
+	NameUniqFeatureTag = "banner_tag_feature_id_tag_id_uniq" //nolint:gochecknoglobals
+	NameFkeyAuthor     = "banner_author_id_fkey"             //nolint:gochecknoglobals
 )
 
 type BannerStorage struct {
-	pool   *pgxpool.Pool
-	logger *zap.SugaredLogger
+	pool          *pgxpool.Pool
+	versionsLimit uint64
+	logger        *zap.SugaredLogger
 }
 
-func NewBannerStorage(pool *pgxpool.Pool) (*BannerStorage, error) {
+// NewBannerStorage creates storage that keeps versionsLimit latest versions of every banner.
+func NewBannerStorage(pool *pgxpool.Pool, versionsLimit uint64) (*BannerStorage, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
 	return &BannerStorage{
-		pool:   pool,
-		logger: logger,
+		pool:          pool,
+		versionsLimit: versionsLimit,
+		logger:        logger,
 	}, nil
 }
 
 func (b *BannerStorage) createBanner(ctx context.Context, tx pgx.Tx, preBanner *models.PreBanner,
-	userID uint64) error {
+	userID uint64) (uint64, error) {
 	var SQLCreateBanner string
 
+	SQLCreateBanner = `INSERT INTO public."banner" (author_id, feature_id, 
+                             content, is_active) VALUES ($1, $2, $3, $4) RETURNING id;`
+	bannerRow := tx.QueryRow(ctx, SQLCreateBanner, userID, preBanner.FeatureID, preBanner.Content,
+		preBanner.IsActive)
+
+	var bannerID uint64
+
+	if err := bannerRow.Scan(&bannerID); err != nil {
+		b.logger.Errorf("in createBanner: preBanner%+v err=%+v", preBanner, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannerID, nil
+}
+
+// addTags gives all tagIDs to banner by one statement.
+func (b *BannerStorage) addTags(ctx context.Context, tx pgx.Tx, tagIDs []uint64, bannerID uint64) error {
+	var SQLAddTags string
+
 	var err error
 
-	SQLCreateBanner = `INSERT INTO public."banner" (author_id, feature_id, 
-                             title, text, url, is_active) VALUES ($1, $2, $3, $4, $5, $6);`
-	_, err = tx.Exec(ctx, SQLCreateBanner, userID, preBanner.FeatureID,
-		preBanner.Content.Title, preBanner.Content.Text, preBanner.Content.URL, preBanner.IsActive)
+	SQLAddTags = `INSERT INTO public."banner_tag" (banner_id, tag_id) SELECT $1, UNNEST($2::BIGINT[]);`
+	_, err = tx.Exec(ctx, SQLAddTags, bannerID, tagIDs)
 
 	if err != nil {
-		b.logger.Errorf("in createBanner: preBanner%+v err=%+v", preBanner, err)
+		b.logger.Errorf("in addTags: tagIDs=%v bannerID=%d", tagIDs, bannerID)
 
 		return fmt.Errorf(myerrors.ErrTemplate, err)
 	}
@@ -59,56 +108,100 @@ func (b *BannerStorage) createBanner(ctx context.Context, tx pgx.Tx, preBanner *
 	return nil
 }
 
-func (b *BannerStorage) addTag(ctx context.Context, tx pgx.Tx, tagID, bannerID uint64) error {
-	var SQLAddTag string
+func (b *BannerStorage) selectFeatureTagConflicts(ctx context.Context, tx pgx.Tx, featureID uint64,
+	tagIDs []uint64, bannerID uint64) ([]models.BannerConflict, error) {
+	SQLSelectConflicts :=
+		`SELECT banner_id, feature_id, tag_id
+		FROM public."banner_tag"
+		WHERE feature_id = $1 AND tag_id = ANY($2) AND banner_id <> $3
+		ORDER BY banner_id, tag_id`
 
-	var err error
+	conflictsRows, err := tx.Query(ctx, SQLSelectConflicts, featureID, tagIDs, bannerID)
+	if err != nil {
+		b.logger.Errorln(err)
 
-	SQLAddTag = `INSERT INTO public."banner_tag" (banner_id, tag_id) VALUES ($1, $2);`
-	_, err = tx.Exec(ctx, SQLAddTag, bannerID, tagID)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
 
+	var curConflict models.BannerConflict
+	var slConflicts []models.BannerConflict
+
+	_, err = pgx.ForEachRow(conflictsRows, []any{
+		&curConflict.BannerID, &curConflict.FeatureID, &curConflict.TagID,
+	}, func() error {
+		slConflicts = append(slConflicts, curConflict)
+
+		return nil
+	})
 	if err != nil {
-		b.logger.Errorf("in addTag: tagID=%d bannerID=%d", tagID, bannerID)
+		b.logger.Errorln(err)
 
-		return fmt.Errorf(myerrors.ErrTemplate, err)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slConflicts, nil
+}
+
+// checkFeatureTagConflicts returns ConflictError if some of (featureID, tagID) pairs
+// already belong to a banner other than bannerID.
+func (b *BannerStorage) checkFeatureTagConflicts(ctx context.Context, tx pgx.Tx, featureID uint64,
+	tagIDs []uint64, bannerID uint64) error {
+	conflicts, err := b.selectFeatureTagConflicts(ctx, tx, featureID, tagIDs, bannerID)
+	if err != nil {
+		return err
+	}
+
+	if len(conflicts) != 0 {
+		return myerrors.NewConflictError(conflicts, "feature_tag_conflict", MessageErrFeatureTagConflict)
 	}
 
 	return nil
 }
 
+// handleFeatureTagUniqErr turns violation of (feature, tag) uniqueness, which happens
+// when concurrent transactions claim the same pairs, into ConflictError.
+func (b *BannerStorage) handleFeatureTagUniqErr(ctx context.Context, err error, featureID uint64,
+	tagIDs []uint64, bannerID uint64) error {
+	if !repository.IsPgConstraintErr(err, repository.PgErrCodeUniqueViolation, NameUniqFeatureTag) {
+		return err
+	}
+
+	errCheck := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		return b.checkFeatureTagConflicts(ctx, tx, featureID, tagIDs, bannerID)
+	})
+	if errCheck != nil {
+		return errCheck
+	}
+
+	return myerrors.NewConflictError([]models.BannerConflict{}, "feature_tag_conflict",
+		MessageErrFeatureTagConflict)
+}
+
 func (b *BannerStorage) AddBanner(ctx context.Context, preBanner *models.PreBanner, userID uint64) (uint64, error) {
 	var bannerID uint64
 
 	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
-		err := b.createBanner(ctx, tx, preBanner, userID)
+		err := b.checkFeatureTagConflicts(ctx, tx, preBanner.FeatureID, preBanner.TagIDs, 0)
 		if err != nil {
-			return fmt.Errorf(myerrors.ErrTemplate, err)
+			return err
 		}
 
-		id, err := repository.GetLastValSeq(ctx, tx, NameSeqBanner)
+		bannerID, err = b.createBanner(ctx, tx, preBanner, userID)
 		if err != nil {
 			return fmt.Errorf(myerrors.ErrTemplate, err)
 		}
 
-		bannerID = id
-
-		return nil
-	})
-	if err != nil {
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
-	}
-
-	err = pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
-		for _, tagID := range preBanner.TagIDs {
-			err = b.addTag(ctx, tx, tagID, bannerID)
-			if err != nil {
-				return fmt.Errorf(myerrors.ErrTemplate, err)
-			}
+		err = b.addTags(ctx, tx, preBanner.TagIDs, bannerID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
 		}
 
-		return nil
+		return b.createBannerVersion(ctx, tx, bannerID, userID)
 	})
 	if err != nil {
+		err = b.handleMissingReferencesErr(ctx, err, preBanner.FeatureID, preBanner.TagIDs)
+		err = b.handleFeatureTagUniqErr(ctx, err, preBanner.FeatureID, preBanner.TagIDs, 0)
+
 		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
@@ -117,22 +210,23 @@ func (b *BannerStorage) AddBanner(ctx context.Context, preBanner *models.PreBann
 
 func (b *BannerStorage) selectBannerContentByID(ctx context.Context,
 	tx pgx.Tx, bannerID uint64,
-) (*models.Content, error) {
-	SQLSelectBanner := `SELECT title, text, url FROM public."banner" WHERE id=$1`
-	bannerContent := &models.Content{} //nolint:exhaustruct
+) (json.RawMessage, uint64, error) {
+	SQLSelectBanner := `SELECT content, revision FROM public."banner" WHERE id=$1`
+	var bannerContent json.RawMessage
+	var revision uint64
 
 	bannerRow := tx.QueryRow(ctx, SQLSelectBanner, bannerID)
-	if err := bannerRow.Scan(&bannerContent.Title, &bannerContent.Text, &bannerContent.URL); err != nil {
+	if err := bannerRow.Scan(&bannerContent, &revision); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrBannerNotFound)
+			return nil, 0, fmt.Errorf(myerrors.ErrTemplate, ErrBannerNotFound)
 		}
 
 		b.logger.Errorf("error with bannerId=%d: %+v", bannerID, err)
 
-		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		return nil, 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return bannerContent, nil
+	return bannerContent, revision, nil
 }
 
 func (b *BannerStorage) selectBannerIsActiveByID(ctx context.Context,
@@ -155,8 +249,35 @@ func (b *BannerStorage) selectBannerIsActiveByID(ctx context.Context,
 	return bannerIsActive, nil
 }
 
-func (b *BannerStorage) GetBanner(ctx context.Context, bannerID uint64, isAdmin bool) (*models.Content, error) {
-	var bannerContent *models.Content
+// GetBannerByID returns the whole current state of banner.
+func (b *BannerStorage) GetBannerByID(ctx context.Context, bannerID uint64) (*models.Banner, error) {
+	SQLSelectBanner :=
+		`SELECT b.id, ARRAY(SELECT bt.tag_id FROM public."banner_tag" bt WHERE bt.banner_id = b.id ORDER BY bt.tag_id),
+			b.feature_id, b.author_id, b.content, b.is_active, b.revision, b.created_at, b.updated_at
+		FROM public."banner" b
+		WHERE b.id = $1`
+
+	banner := &models.Banner{} //nolint:exhaustruct
+
+	bannerRow := b.pool.QueryRow(ctx, SQLSelectBanner, bannerID)
+	if err := bannerRow.Scan(&banner.BannerID, &banner.TagIDs, &banner.FeatureID, &banner.AuthorID, &banner.Content,
+		&banner.IsActive, &banner.Revision, &banner.CreatedAt, &banner.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrBannerNotFound)
+		}
+
+		b.logger.Errorf("error with bannerId=%d: %+v", bannerID, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return banner, nil
+}
+
+// GetBanner returns content of banner and its revision.
+func (b *BannerStorage) GetBanner(ctx context.Context, bannerID uint64, isAdmin bool) (json.RawMessage, uint64, error) {
+	var bannerContent json.RawMessage
+	var revision uint64
 
 	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
 		isActive, err := b.selectBannerIsActiveByID(ctx, tx, bannerID)
@@ -167,11 +288,62 @@ func (b *BannerStorage) GetBanner(ctx context.Context, bannerID uint64, isAdmin
 			return fmt.Errorf(myerrors.ErrTemplate, ErrNotAdminGetNotActiveBanner)
 		}
 
-		bannerContentInner, err := b.selectBannerContentByID(ctx, tx, bannerID)
+		bannerContentInner, revisionInner, err := b.selectBannerContentByID(ctx, tx, bannerID)
 		if err != nil {
 			return err
 		}
 
+		bannerContent, revision = bannerContentInner, revisionInner
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannerContent, revision, nil
+}
+
+func (b *BannerStorage) selectUserBannerByFeatureAndTag(ctx context.Context, tx pgx.Tx,
+	featureID uint64, tagID uint64,
+) (json.RawMessage, bool, error) {
+	SQLSelectUserBanner :=
+		`SELECT b.content, b.is_active
+		FROM public."banner" b
+		JOIN public."banner_tag" bt ON b.id = bt.banner_id
+		WHERE b.feature_id = $1 AND bt.tag_id = $2
+		ORDER BY b.is_active DESC
+		LIMIT 1`
+	var bannerContent json.RawMessage
+	var bannerIsActive bool
+
+	bannerRow := tx.QueryRow(ctx, SQLSelectUserBanner, featureID, tagID)
+	if err := bannerRow.Scan(&bannerContent, &bannerIsActive); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, fmt.Errorf(myerrors.ErrTemplate, ErrBannerNotFound)
+		}
+
+		b.logger.Errorf("error with featureID=%d tagID=%d: %+v", featureID, tagID, err)
+
+		return nil, false, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannerContent, bannerIsActive, nil
+}
+
+func (b *BannerStorage) GetUserBanner(ctx context.Context, featureID uint64, tagID uint64,
+	isAdmin bool) (json.RawMessage, error) {
+	var bannerContent json.RawMessage
+
+	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		bannerContentInner, isActive, err := b.selectUserBannerByFeatureAndTag(ctx, tx, featureID, tagID)
+		if err != nil {
+			return err
+		}
+		if !isActive && !isAdmin {
+			return fmt.Errorf(myerrors.ErrTemplate, ErrNotAdminGetNotActiveBanner)
+		}
+
 		bannerContent = bannerContentInner
 
 		return nil
@@ -183,10 +355,10 @@ func (b *BannerStorage) GetBanner(ctx context.Context, bannerID uint64, isAdmin
 	return bannerContent, nil
 }
 
-func (b *BannerStorage) deleteBanner(ctx context.Context, tx pgx.Tx, bannerID uint64, userID uint64) error {
-	SQLDeleteBanner := `DELETE FROM public."banner" WHERE id=$1 AND author_id=$2`
+func (b *BannerStorage) deleteBanner(ctx context.Context, tx pgx.Tx, bannerID uint64) error {
+	SQLDeleteBanner := `DELETE FROM public."banner" WHERE id=$1`
 
-	result, err := tx.Exec(ctx, SQLDeleteBanner, bannerID, userID)
+	result, err := tx.Exec(ctx, SQLDeleteBanner, bannerID)
 	if err != nil {
 		b.logger.Errorln(err)
 
@@ -195,15 +367,16 @@ func (b *BannerStorage) deleteBanner(ctx context.Context, tx pgx.Tx, bannerID ui
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf(myerrors.ErrTemplate, ErrNoAffectedBannerRows)
+		return fmt.Errorf(myerrors.ErrTemplate, ErrBannerNotFound)
 	}
 
 	return nil
 }
 
-func (b *BannerStorage) DeleteBanner(ctx context.Context, bannerID uint64, userID uint64) error {
+// DeleteBanner deletes banner whoever its author is, permission to delete is checked by caller.
+func (b *BannerStorage) DeleteBanner(ctx context.Context, bannerID uint64) error {
 	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
-		err := b.deleteBanner(ctx, tx, bannerID, userID)
+		err := b.deleteBanner(ctx, tx, bannerID)
 		if err != nil {
 			return err
 		}
@@ -219,43 +392,122 @@ func (b *BannerStorage) DeleteBanner(ctx context.Context, bannerID uint64, userI
 	return nil
 }
 
+// NewRevisionConflictError is returned when banner is not at the revision client has based its change on.
+func NewRevisionConflictError(bannerID uint64, currentRevision uint64) error {
+	return myerrors.NewConflictError(models.RevisionConflict{BannerID: bannerID, CurrentRevision: currentRevision},
+		"revision_conflict", MessageErrRevisionConflict)
+}
+
+// explainNotUpdatedBanner tells why update of banner affected no rows.
+func (b *BannerStorage) explainNotUpdatedBanner(ctx context.Context, tx pgx.Tx, bannerID uint64,
+	expectedRevision uint64) error {
+	SQLSelectRevision := `SELECT revision FROM public."banner" WHERE id=$1`
+
+	var revision uint64
+
+	revisionRow := tx.QueryRow(ctx, SQLSelectRevision, bannerID)
+	if err := revisionRow.Scan(&revision); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf(myerrors.ErrTemplate, ErrBannerNotFound)
+		}
+
+		b.logger.Errorf("error with bannerId=%d: %+v", bannerID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if expectedRevision != 0 && revision != expectedRevision {
+		return NewRevisionConflictError(bannerID, revision)
+	}
+
+	return fmt.Errorf(myerrors.ErrTemplate, ErrNoAffectedBannerRows)
+}
+
+// updateBanner updates banner if its revision equals expectedRevision, 0 skips this check.
+// It returns the new revision of banner. Author of banner is kept, permission to update is checked by caller.
 func (b *BannerStorage) updateBanner(ctx context.Context, tx pgx.Tx, preBanner *models.PreBanner,
-	bannerID uint64, userID uint64) error {
-	var SQLCreateBanner string
+	bannerID uint64, expectedRevision uint64) (uint64, error) {
+	var SQLUpdateBanner string
 
-	var err error
+	SQLUpdateBanner = `UPDATE public."banner" SET feature_id = $1, content = $2, is_active = $3 
+                             WHERE id=$4 AND ($5::BIGINT = 0 OR revision = $5) RETURNING revision;`
+	revisionRow := tx.QueryRow(ctx, SQLUpdateBanner, preBanner.FeatureID, preBanner.Content, preBanner.IsActive,
+		bannerID, expectedRevision)
 
-	SQLCreateBanner = `UPDATE public."banner" SET feature_id = $1, title = $2, text = $3, url = $4, is_active = $5 
-                             WHERE author_id=$7 AND id=$8;`
-	_, err = tx.Exec(ctx, SQLCreateBanner, preBanner.FeatureID,
-		preBanner.Content.Title, preBanner.Content.Text, preBanner.Content.URL, preBanner.IsActive, userID, bannerID)
+	var revision uint64
+
+	if err := revisionRow.Scan(&revision); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, b.explainNotUpdatedBanner(ctx, tx, bannerID, expectedRevision)
+		}
 
-	if err != nil {
 		b.logger.Errorf("in updateBanner: preBanner%+v err=%+v", preBanner, err)
 
-		return fmt.Errorf(myerrors.ErrTemplate, err)
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return nil
+	return revision, nil
+}
+
+// replaceBanner makes preBanner the current state of banner, including its tag set, and saves
+// it as a new version. It returns the new revision of banner.
+func (b *BannerStorage) replaceBanner(ctx context.Context, tx pgx.Tx, preBanner *models.PreBanner,
+	bannerID uint64, userID uint64, expectedRevision uint64) (uint64, error) {
+	err := b.checkFeatureTagConflicts(ctx, tx, preBanner.FeatureID, preBanner.TagIDs, bannerID)
+	if err != nil {
+		return 0, err
+	}
+
+	// old tags are removed before feature is changed, so they never clash under the new feature
+	err = b.deleteTags(ctx, tx, bannerID)
+	if err != nil {
+		return 0, err
+	}
+
+	revision, err := b.updateBanner(ctx, tx, preBanner, bannerID, expectedRevision)
+	if err != nil {
+		return 0, err
+	}
+
+	err = b.addTags(ctx, tx, preBanner.TagIDs, bannerID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = b.createBannerVersion(ctx, tx, bannerID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return revision, nil
 }
 
+// UpdateBanner replaces feature, tags, content and activity of banner if its revision
+// equals expectedRevision and returns the new revision.
 func (b *BannerStorage) UpdateBanner(ctx context.Context, newBanner *models.PreBanner, bannerID uint64,
-	userID uint64) error {
+	userID uint64, expectedRevision uint64) (uint64, error) {
+	var revision uint64
+
 	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
-		err := b.updateBanner(ctx, tx, newBanner, userID, bannerID)
+		revisionInner, err := b.replaceBanner(ctx, tx, newBanner, bannerID, userID, expectedRevision)
 		if err != nil {
 			return err
 		}
 
+		revision = revisionInner
+
 		return nil
 	})
 	if err != nil {
 		b.logger.Errorln(err)
 
-		return fmt.Errorf(myerrors.ErrTemplate, err)
+		err = b.handleMissingReferencesErr(ctx, err, newBanner.FeatureID, newBanner.TagIDs)
+		err = b.handleFeatureTagUniqErr(ctx, err, newBanner.FeatureID, newBanner.TagIDs, bannerID)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return nil
+	return revision, nil
 }
 
 func (b *BannerStorage) selectTagsIDsByBannerID(ctx context.Context, tx pgx.Tx,
@@ -293,18 +545,17 @@ func (b *BannerStorage) selectTagsIDsByBannerID(ctx context.Context, tx pgx.Tx,
 
 func (b *BannerStorage) selectBannersInFeedWithWhereLimitOffset(ctx context.Context, tx pgx.Tx,
 	featureID uint64, tagID uint64, limit uint64, offset uint64) ([]*models.Banner, error) {
-	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Select("id, feature_id, " +
-		"title, text, url, is_active, created_at, updated_at").From(`public."banner"`)
+	// columns are qualified, because banner_tag joined for tag has columns with the same names
+	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Select("b.id, b.feature_id, " +
+		"b.author_id, b.content, b.is_active, b.revision, b.created_at, b.updated_at").From(`public."banner" b`)
 
-	if featureID != 0 || tagID != 0 {
-		if featureID != 0 {
-			query = query.Where(squirrel.Eq{"feature_id": featureID})
-		}
-		if tagID != 0 {
-			query = query.Join(`public."banner_tag" bt ON public."banner".id = bt.banner_id`).
-				Join(`public."tag" t ON bt.tag_id = t.id`).
-				Where(squirrel.Eq{"t.id": tagID})
-		}
+	if featureID != 0 {
+		query = query.Where(squirrel.Eq{"b.feature_id": featureID})
+	}
+
+	if tagID != 0 {
+		query = query.Join(`public."banner_tag" bt ON b.id = bt.banner_id`).
+			Where(squirrel.Eq{"bt.tag_id": tagID})
 	}
 
 	query = query.Limit(limit).Offset(offset)
@@ -328,15 +579,17 @@ func (b *BannerStorage) selectBannersInFeedWithWhereLimitOffset(ctx context.Cont
 	var slBanner []*models.Banner
 
 	_, err = pgx.ForEachRow(rowsBanners, []any{
-		&curBanner.BannerID, &curBanner.FeatureID,
-		&curBanner.Content.Title, &curBanner.Content.Text, &curBanner.Content.URL,
-		&curBanner.IsActive, &curBanner.CreatedAt, &curBanner.UpdatedAt,
+		&curBanner.BannerID, &curBanner.FeatureID, &curBanner.AuthorID,
+		&curBanner.Content,
+		&curBanner.IsActive, &curBanner.Revision, &curBanner.CreatedAt, &curBanner.UpdatedAt,
 	}, func() error {
-		slBanner = append(slBanner, &models.Banner{
+		slBanner = append(slBanner, &models.Banner{ //nolint:exhaustruct
 			BannerID:  curBanner.BannerID,
 			FeatureID: curBanner.FeatureID,
+			AuthorID:  curBanner.AuthorID,
 			Content:   curBanner.Content,
 			IsActive:  curBanner.IsActive,
+			Revision:  curBanner.Revision,
 			CreatedAt: curBanner.CreatedAt,
 			UpdatedAt: curBanner.UpdatedAt,
 		})
@@ -381,3 +634,85 @@ func (b *BannerStorage) GetBannersList(ctx context.Context, featureID uint64, ta
 
 	return slBanners, nil
 }
+
+// SetBannerAuthor makes user with authorID author of banner if its revision equals expectedRevision
+// and returns the new revision. Author is kept only for attribution, it gives no rights on banner.
+// Transfer is saved as a new version made by user with userID, like other changes of banner.
+func (b *BannerStorage) SetBannerAuthor(ctx context.Context, bannerID uint64, authorID uint64, userID uint64,
+	expectedRevision uint64) (uint64, error) {
+	SQLSetBannerAuthor := `UPDATE public."banner" SET author_id = $1
+		WHERE id = $2 AND ($3::BIGINT = 0 OR revision = $3) RETURNING revision;`
+
+	var revision uint64
+
+	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		revisionRow := tx.QueryRow(ctx, SQLSetBannerAuthor, authorID, bannerID, expectedRevision)
+		if err := revisionRow.Scan(&revision); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return b.explainNotUpdatedBanner(ctx, tx, bannerID, expectedRevision)
+			}
+
+			return err //nolint:wrapcheck
+		}
+
+		return b.createBannerVersion(ctx, tx, bannerID, userID)
+	})
+	if err != nil {
+		if repository.IsPgConstraintErr(err, repository.PgErrCodeForeignKeyViolation, NameFkeyAuthor) {
+			return 0, fmt.Errorf(myerrors.ErrTemplate, ErrBannerAuthorNotFound)
+		}
+
+		b.logger.Errorf("in SetBannerAuthor: bannerID=%d authorID=%d err=%+v", bannerID, authorID, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return revision, nil
+}
+
+// GetAllBanners returns every banner with its tags, it is used to build in-memory index of banners.
+func (b *BannerStorage) GetAllBanners(ctx context.Context) ([]*models.Banner, error) {
+	SQLSelectAllBanners :=
+		`SELECT b.id, b.feature_id,
+			COALESCE(ARRAY_AGG(bt.tag_id) FILTER (WHERE bt.tag_id IS NOT NULL), '{}'),
+			b.content, b.is_active, b.revision, b.created_at, b.updated_at
+		FROM public."banner" b
+		LEFT JOIN public."banner_tag" bt ON b.id = bt.banner_id
+		GROUP BY b.id`
+
+	rowsBanners, err := b.pool.Query(ctx, SQLSelectAllBanners)
+	if err != nil {
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curBanner := new(models.Banner)
+
+	var slBanners []*models.Banner
+
+	_, err = pgx.ForEachRow(rowsBanners, []any{
+		&curBanner.BannerID, &curBanner.FeatureID, &curBanner.TagIDs,
+		&curBanner.Content, &curBanner.IsActive, &curBanner.Revision, &curBanner.CreatedAt, &curBanner.UpdatedAt,
+	}, func() error {
+		slBanners = append(slBanners, &models.Banner{ //nolint:exhaustruct
+			BannerID:  curBanner.BannerID,
+			TagIDs:    curBanner.TagIDs,
+			FeatureID: curBanner.FeatureID,
+			Content:   curBanner.Content,
+			IsActive:  curBanner.IsActive,
+			Revision:  curBanner.Revision,
+			CreatedAt: curBanner.CreatedAt,
+			UpdatedAt: curBanner.UpdatedAt,
+		})
+
+		return nil
+	})
+	if err != nil {
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slBanners, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var (
+	errScan  = errors.New("scan failed")
+	errQuery = errors.New("query failed")
+)
+
+type fakeRow struct {
+	id  uint64
+	err error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	*dest[0].(*uint64) = r.id //nolint:forcetypeassert
+
+	return nil
+}
+
+// fakeTx remembers statements and returns row from every QueryRow.
+type fakeTx struct {
+	pgx.Tx
+	row     fakeRow
+	queries []string
+	args    [][]any
+}
+
+func (t *fakeTx) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	t.queries = append(t.queries, sql)
+	t.args = append(t.args, args)
+
+	return t.row
+}
+
+// Query only remembers statement, rows are never read in these tests.
+func (t *fakeTx) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	t.queries = append(t.queries, sql)
+	t.args = append(t.args, args)
+
+	return nil, errQuery
+}
+
+func (t *fakeTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	t.queries = append(t.queries, sql)
+	t.args = append(t.args, args)
+
+	return pgconn.CommandTag{}, nil
+}
+
+func newTestBannerStorage() *BannerStorage {
+	return &BannerStorage{pool: nil, versionsLimit: 3, logger: zap.NewNop().Sugar()}
+}
+
+func TestCreateBannerReturnsID(t *testing.T) {
+	t.Parallel()
+
+	preBanner := &models.PreBanner{FeatureID: 2, Content: []byte(`{}`), IsActive: true} //nolint:exhaustruct
+
+	tx := &fakeTx{row: fakeRow{id: 42}} //nolint:exhaustruct
+
+	bannerID, err := newTestBannerStorage().createBanner(context.Background(), tx, preBanner, 7)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if bannerID != 42 {
+		t.Errorf("bannerID = %d, want 42", bannerID)
+	}
+
+	if len(tx.queries) != 1 || !strings.Contains(tx.queries[0], "RETURNING id") {
+		t.Errorf("queries = %q, want single INSERT ... RETURNING id", tx.queries)
+	}
+
+	tx = &fakeTx{row: fakeRow{err: errScan}} //nolint:exhaustruct
+
+	if _, err = newTestBannerStorage().createBanner(context.Background(), tx, preBanner, 7); !errors.Is(err, errScan) {
+		t.Errorf("err = %v, want %v", err, errScan)
+	}
+}
+
+func TestAddTagsOneStatement(t *testing.T) {
+	t.Parallel()
+
+	tx := &fakeTx{} //nolint:exhaustruct
+
+	if err := newTestBannerStorage().addTags(context.Background(), tx, []uint64{1, 2, 3}, 42); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if len(tx.queries) != 1 {
+		t.Fatalf("%d statements executed, want 1", len(tx.queries))
+	}
+
+	if tx.args[0][0] != uint64(42) || !slices.Equal(tx.args[0][1].([]uint64), []uint64{1, 2, 3}) { //nolint:forcetypeassert
+		t.Errorf("args = %v, want [42 [1 2 3]]", tx.args[0])
+	}
+}
+
+func TestSelectBannersListQuery(t *testing.T) {
+	t.Parallel()
+
+	const columns = "SELECT b.id, b.feature_id, b.author_id, b.content, b.is_active, b.revision, b.created_at, " +
+		"b.updated_at FROM public.\"banner\" b"
+
+	tests := []struct {
+		name      string
+		featureID uint64
+		tagID     uint64
+		wantSQL   string
+		wantArgs  []any
+	}{
+		{
+			name:    "all banners",
+			wantSQL: columns + " LIMIT 10 OFFSET 20",
+		},
+		{
+			name:      "feature",
+			featureID: 3,
+			wantSQL:   columns + " WHERE b.feature_id = $1 LIMIT 10 OFFSET 20",
+			wantArgs:  []any{uint64(3)},
+		},
+		{
+			name:     "tag",
+			tagID:    5,
+			wantSQL:  columns + ` JOIN public."banner_tag" bt ON b.id = bt.banner_id WHERE bt.tag_id = $1 LIMIT 10 OFFSET 20`,
+			wantArgs: []any{uint64(5)},
+		},
+		{
+			name:      "feature and tag",
+			featureID: 3,
+			tagID:     5,
+			wantSQL: columns + ` JOIN public."banner_tag" bt ON b.id = bt.banner_id` +
+				" WHERE b.feature_id = $1 AND bt.tag_id = $2 LIMIT 10 OFFSET 20",
+			wantArgs: []any{uint64(3), uint64(5)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tx := &fakeTx{} //nolint:exhaustruct
+
+			_, err := newTestBannerStorage().selectBannersInFeedWithWhereLimitOffset(context.Background(), tx,
+				tt.featureID, tt.tagID, 10, 20)
+			if !errors.Is(err, errQuery) {
+				t.Fatalf("err = %v, want %v", err, errQuery)
+			}
+
+			if tx.queries[0] != tt.wantSQL {
+				t.Errorf("sql = %s, want %s", tx.queries[0], tt.wantSQL)
+			}
+
+			if !slices.Equal(tx.args[0], tt.wantArgs) {
+				t.Errorf("args = %v, want %v", tx.args[0], tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrBannerVersionNotFound = myerrors.NewNotFoundError("banner_version_not_found", myerrors.Message{
+		RU: "Эта версия баннера не найдена",
+		EN: "Banner version is not found",
+	})
+)
+
+// createBannerVersion saves current state of banner as its next version
+// and removes versions beyond versionsLimit.
+func (b *BannerStorage) createBannerVersion(ctx context.Context, tx pgx.Tx, bannerID uint64, userID uint64) error {
+	SQLCreateBannerVersion :=
+		`INSERT INTO public."banner_version" (banner_id, version, author_id, feature_id, tag_ids, content, is_active)
+		SELECT b.id,
+			COALESCE((SELECT MAX(v.version) FROM public."banner_version" v WHERE v.banner_id = b.id), 0) + 1,
+			$2, b.feature_id,
+			COALESCE((SELECT ARRAY_AGG(bt.tag_id ORDER BY bt.tag_id) FROM public."banner_tag" bt
+				WHERE bt.banner_id = b.id), '{}'),
+			b.content, b.is_active
+		FROM public."banner" b
+		WHERE b.id = $1
+		RETURNING version`
+
+	var version uint64
+
+	versionRow := tx.QueryRow(ctx, SQLCreateBannerVersion, bannerID, userID)
+	if err := versionRow.Scan(&version); err != nil {
+		b.logger.Errorf("in createBannerVersion: bannerID=%d err=%+v", bannerID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if version <= b.versionsLimit {
+		return nil
+	}
+
+	SQLPruneBannerVersions := `DELETE FROM public."banner_version" WHERE banner_id = $1 AND version <= $2`
+
+	_, err := tx.Exec(ctx, SQLPruneBannerVersions, bannerID, version-b.versionsLimit)
+	if err != nil {
+		b.logger.Errorf("in createBannerVersion: bannerID=%d err=%+v", bannerID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (b *BannerStorage) GetBannerVersions(ctx context.Context, bannerID uint64) ([]*models.BannerVersion, error) {
+	SQLSelectBannerVersions :=
+		`SELECT banner_id, version, author_id, tag_ids, feature_id, content, is_active, created_at
+		FROM public."banner_version"
+		WHERE banner_id = $1
+		ORDER BY version DESC`
+
+	versionsRows, err := b.pool.Query(ctx, SQLSelectBannerVersions, bannerID)
+	if err != nil {
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curVersion := new(models.BannerVersion)
+
+	var slVersions []*models.BannerVersion
+
+	_, err = pgx.ForEachRow(versionsRows, []any{
+		&curVersion.BannerID, &curVersion.Version, &curVersion.AuthorID, &curVersion.TagIDs,
+		&curVersion.FeatureID, &curVersion.Content, &curVersion.IsActive, &curVersion.CreatedAt,
+	}, func() error {
+		slVersions = append(slVersions, &models.BannerVersion{
+			BannerID:  curVersion.BannerID,
+			Version:   curVersion.Version,
+			AuthorID:  curVersion.AuthorID,
+			TagIDs:    curVersion.TagIDs,
+			FeatureID: curVersion.FeatureID,
+			Content:   curVersion.Content,
+			IsActive:  curVersion.IsActive,
+			CreatedAt: curVersion.CreatedAt,
+		})
+
+		return nil
+	})
+	if err != nil {
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if len(slVersions) == 0 {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrBannerNotFound)
+	}
+
+	return slVersions, nil
+}
+
+func (b *BannerStorage) selectBannerVersion(ctx context.Context, tx pgx.Tx, bannerID uint64,
+	version uint64) (*models.BannerVersion, error) {
+	SQLSelectBannerVersion :=
+		`SELECT banner_id, version, author_id, tag_ids, feature_id, content, is_active, created_at
+		FROM public."banner_version"
+		WHERE banner_id = $1 AND version = $2`
+
+	bannerVersion := &models.BannerVersion{} //nolint:exhaustruct
+
+	versionRow := tx.QueryRow(ctx, SQLSelectBannerVersion, bannerID, version)
+	if err := versionRow.Scan(&bannerVersion.BannerID, &bannerVersion.Version, &bannerVersion.AuthorID,
+		&bannerVersion.TagIDs, &bannerVersion.FeatureID, &bannerVersion.Content, &bannerVersion.IsActive,
+		&bannerVersion.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrBannerVersionNotFound)
+		}
+
+		b.logger.Errorf("error with bannerID=%d version=%d: %+v", bannerID, version, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannerVersion, nil
+}
+
+func (b *BannerStorage) GetBannerVersion(ctx context.Context, bannerID uint64,
+	version uint64) (*models.BannerVersion, error) {
+	var bannerVersion *models.BannerVersion
+
+	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		bannerVersionInner, err := b.selectBannerVersion(ctx, tx, bannerID, version)
+		if err != nil {
+			return err
+		}
+
+		bannerVersion = bannerVersionInner
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannerVersion, nil
+}
+
+func (b *BannerStorage) deleteTags(ctx context.Context, tx pgx.Tx, bannerID uint64) error {
+	SQLDeleteTags := `DELETE FROM public."banner_tag" WHERE banner_id = $1`
+
+	_, err := tx.Exec(ctx, SQLDeleteTags, bannerID)
+	if err != nil {
+		b.logger.Errorf("in deleteTags: bannerID=%d err=%+v", bannerID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// RestoreBannerVersion makes feature, tags, content and activity of given version current.
+// Restoring is an update of banner, so it is saved as a new version too and
+// is checked against expectedRevision like UpdateBanner. It returns the new revision.
+func (b *BannerStorage) RestoreBannerVersion(ctx context.Context, bannerID uint64, version uint64,
+	userID uint64, expectedRevision uint64) (uint64, error) {
+	var bannerVersion *models.BannerVersion
+
+	var revision uint64
+
+	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		bannerVersionInner, err := b.selectBannerVersion(ctx, tx, bannerID, version)
+		if err != nil {
+			return err
+		}
+
+		bannerVersion = bannerVersionInner
+
+		revision, err = b.replaceBanner(ctx, tx, &models.PreBanner{
+			TagIDs:    bannerVersion.TagIDs,
+			FeatureID: bannerVersion.FeatureID,
+			Content:   bannerVersion.Content,
+			IsActive:  bannerVersion.IsActive,
+		}, bannerID, userID, expectedRevision)
+
+		return err
+	})
+	if err != nil {
+		b.logger.Errorln(err)
+
+		if bannerVersion != nil {
+			err = b.handleMissingReferencesErr(ctx, err, bannerVersion.FeatureID, bannerVersion.TagIDs)
+			err = b.handleFeatureTagUniqErr(ctx, err, bannerVersion.FeatureID, bannerVersion.TagIDs, bannerID)
+		}
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	featurerepo "github.com/SanExpett/banners-backend/internal/feature/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrFeatureSchemaNotFound = myerrors.NewNotFoundError("feature_schema_not_found", myerrors.Message{
+		RU: "Схема контента для этой фичи не найдена",
+		EN: "Content schema of feature is not found",
+	})
+)
+
+func (b *BannerStorage) lockFeature(ctx context.Context, tx pgx.Tx, featureID uint64) error {
+	SQLLockFeature := `SELECT id FROM public."feature" WHERE id=$1 FOR UPDATE`
+
+	var id uint64
+
+	featureRow := tx.QueryRow(ctx, SQLLockFeature, featureID)
+	if err := featureRow.Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf(myerrors.ErrTemplate, featurerepo.ErrFeatureNotFound)
+		}
+
+		b.logger.Errorf("error with featureID=%d: %+v", featureID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (b *BannerStorage) createFeatureSchema(ctx context.Context, tx pgx.Tx, featureID uint64,
+	schema json.RawMessage, userID uint64) (uint64, error) {
+	SQLCreateFeatureSchema :=
+		`INSERT INTO public."feature_schema" (feature_id, version, schema, author_id)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3
+		FROM public."feature_schema"
+		WHERE feature_id = $1
+		RETURNING version`
+
+	var version uint64
+
+	versionRow := tx.QueryRow(ctx, SQLCreateFeatureSchema, featureID, schema, userID)
+	if err := versionRow.Scan(&version); err != nil {
+		b.logger.Errorf("in createFeatureSchema: featureID=%d err=%+v", featureID, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return version, nil
+}
+
+// AddFeatureSchema saves schema as the next version of feature schema and returns this version.
+func (b *BannerStorage) AddFeatureSchema(ctx context.Context, featureID uint64, schema json.RawMessage,
+	userID uint64) (uint64, error) {
+	var version uint64
+
+	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		err := b.lockFeature(ctx, tx, featureID)
+		if err != nil {
+			return err
+		}
+
+		versionInner, err := b.createFeatureSchema(ctx, tx, featureID, schema, userID)
+		if err != nil {
+			return err
+		}
+
+		version = versionInner
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return version, nil
+}
+
+// GetFeatureSchema returns given version of feature schema or the current one if version is 0.
+func (b *BannerStorage) GetFeatureSchema(ctx context.Context, featureID uint64,
+	version uint64) (*models.FeatureSchema, error) {
+	SQLSelectFeatureSchema :=
+		`SELECT feature_id, version, schema, author_id, created_at
+		FROM public."feature_schema"
+		WHERE feature_id = $1 AND ($2 = 0 OR version = $2)
+		ORDER BY version DESC
+		LIMIT 1`
+
+	featureSchema := &models.FeatureSchema{} //nolint:exhaustruct
+
+	featureSchemaRow := b.pool.QueryRow(ctx, SQLSelectFeatureSchema, featureID, version)
+	if err := featureSchemaRow.Scan(&featureSchema.FeatureID, &featureSchema.Version, &featureSchema.Schema,
+		&featureSchema.AuthorID, &featureSchema.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrFeatureSchemaNotFound)
+		}
+
+		b.logger.Errorf("error with featureID=%d version=%d: %+v", featureID, version, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return featureSchema, nil
+}
+
+func (b *BannerStorage) GetFeatureSchemasList(ctx context.Context, featureID uint64) ([]*models.FeatureSchema,
+	error) {
+	SQLSelectFeatureSchemas :=
+		`SELECT feature_id, version, schema, author_id, created_at
+		FROM public."feature_schema"
+		WHERE feature_id = $1
+		ORDER BY version DESC`
+
+	featureSchemasRows, err := b.pool.Query(ctx, SQLSelectFeatureSchemas, featureID)
+	if err != nil {
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curFeatureSchema := new(models.FeatureSchema)
+
+	var slFeatureSchemas []*models.FeatureSchema
+
+	_, err = pgx.ForEachRow(featureSchemasRows, []any{
+		&curFeatureSchema.FeatureID, &curFeatureSchema.Version, &curFeatureSchema.Schema,
+		&curFeatureSchema.AuthorID, &curFeatureSchema.CreatedAt,
+	}, func() error {
+		slFeatureSchemas = append(slFeatureSchemas, &models.FeatureSchema{
+			FeatureID: curFeatureSchema.FeatureID,
+			Version:   curFeatureSchema.Version,
+			Schema:    curFeatureSchema.Schema,
+			AuthorID:  curFeatureSchema.AuthorID,
+			CreatedAt: curFeatureSchema.CreatedAt,
+		})
+
+		return nil
+	})
+	if err != nil {
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slFeatureSchemas, nil
+}
+
+// GetBannersContentByFeature returns id and content of every banner of feature.
+func (b *BannerStorage) GetBannersContentByFeature(ctx context.Context, featureID uint64) ([]*models.Banner,
+	error) {
+	SQLSelectBannersContent :=
+		`SELECT id, content
+		FROM public."banner"
+		WHERE feature_id = $1
+		ORDER BY id`
+
+	bannersRows, err := b.pool.Query(ctx, SQLSelectBannersContent, featureID)
+	if err != nil {
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curBanner := new(models.Banner)
+
+	var slBanners []*models.Banner
+
+	_, err = pgx.ForEachRow(bannersRows, []any{
+		&curBanner.BannerID, &curBanner.Content,
+	}, func() error {
+		slBanners = append(slBanners, &models.Banner{ //nolint:exhaustruct
+			BannerID:  curBanner.BannerID,
+			FeatureID: featureID,
+			Content:   curBanner.Content,
+		})
+
+		return nil
+	})
+	if err != nil {
+		b.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slBanners, nil
+}
@@ -0,0 +1,48 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+)
+
+var (
+	MessageErrPermissionDenied = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Нет права %s на баннеры фичи %d",
+		EN: "No %s permission on banners of feature %d",
+	}
+	MessageErrPermissionDeniedEverywhere = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Нет права %s на баннеры всех фич",
+		EN: "No %s permission on banners of all features",
+	}
+)
+
+// Authorize returns error unless principal has permission on banners of feature featureID,
+// featureID 0 means banners of all features.
+func Authorize(principal *models.Principal, permission models.Permission, featureID uint64) error {
+	if principal.Can(permission, featureID) {
+		return nil
+	}
+
+	if featureID == 0 {
+		return myerrors.NewForbiddenError("permission_denied", MessageErrPermissionDeniedEverywhere, permission)
+	}
+
+	return myerrors.NewForbiddenError("permission_denied", MessageErrPermissionDenied, permission, featureID)
+}
+
+// authorizeBanner is Authorize on the current feature of banner with bannerID, which is returned.
+func (b *BannerService) authorizeBanner(ctx context.Context, principal *models.Principal,
+	permission models.Permission, bannerID uint64) (*models.Banner, error) {
+	banner, err := b.storage.GetBannerByID(ctx, bannerID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if err := Authorize(principal, permission, banner.FeatureID); err != nil {
+		return nil, err
+	}
+
+	return banner, nil
+}
@@ -0,0 +1,236 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"strings"
+	"testing"
+)
+
+// testEditor returns user which can read and write banners of feature.
+func testEditor(featureID uint64) *models.Principal {
+	principal := testPrincipal(9)
+	principal.Grants = models.GrantsOf([]*models.RoleBinding{
+		{ID: 1, UserID: 9, Role: models.RoleEditor, FeatureID: featureID},
+	})
+
+	return principal
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	editor := testEditor(3)
+
+	if err := Authorize(editor, models.PermBannerWrite, 3); err != nil {
+		t.Errorf("editor can not write banners of own feature: %v", err)
+	}
+
+	err := Authorize(editor, models.PermBannerDelete, 3)
+	if !isErrKind(err, myerrors.KindForbidden) || !strings.Contains(err.Error(), "3") {
+		t.Errorf("err = %v, want forbidden error naming feature 3", err)
+	}
+
+	if err := Authorize(editor, models.PermBannerWrite, 0); !isErrKind(err, myerrors.KindForbidden) {
+		t.Errorf("err for all features = %v, want forbidden error", err)
+	}
+
+	if err := Authorize(testAdmin(), models.PermBannerDelete, 0); err != nil {
+		t.Errorf("admin can not delete banners of all features: %v", err)
+	}
+}
+
+func TestBannerServiceAuthorize(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"tag_ids": [1], "feature_id": 3, "content": {"title": "t"}, "is_active": true}`
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		call    func(bannerService *BannerService) error
+		wantErr bool
+	}{
+		{
+			name: "editor adds banner of own feature",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.AddBanner(ctx, strings.NewReader(body), testEditor(3))
+
+				return err
+			},
+		},
+		{
+			name: "editor adds banner of other feature",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.AddBanner(ctx, strings.NewReader(body), testEditor(4))
+
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "editor updates banner of own feature",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.UpdateBanner(ctx, strings.NewReader(`{"is_active": false}`), 1,
+					testEditor(3), 4)
+
+				return err
+			},
+		},
+		{
+			name: "editor moves banner to feature without grant",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.UpdateBanner(ctx, strings.NewReader(`{"feature_id": 4}`), 1,
+					testEditor(3), 4)
+
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "viewer updates banner",
+			call: func(bannerService *BannerService) error {
+				viewer := testPrincipal(9)
+				viewer.Grants = []models.Grant{{Permission: models.PermBannerRead, FeatureID: 3}}
+
+				_, err := bannerService.UpdateBanner(ctx, strings.NewReader(`{"is_active": false}`), 1, viewer, 4)
+
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "editor lists banners of own feature",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.GetBannersList(ctx, 3, 0, 10, 0, testEditor(3))
+
+				return err
+			},
+		},
+		{
+			name: "editor lists banners of other feature",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.GetBannersList(ctx, 4, 0, 10, 0, testEditor(3))
+
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "editor lists banners of all features",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.GetBannersList(ctx, 0, 1, 10, 0, testEditor(3))
+
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "editor deletes banners of feature",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.AddBannerDeleteJob(ctx, 3, 0, testEditor(3))
+
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "manager of feature deletes banners of tag",
+			call: func(bannerService *BannerService) error {
+				manager := testPrincipal(9)
+				manager.Grants = []models.Grant{{Permission: models.PermBannerDelete, FeatureID: 3}}
+
+				_, err := bannerService.AddBannerDeleteJob(ctx, 0, 1, manager)
+
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "manager deletes banner of other author",
+			call: func(bannerService *BannerService) error {
+				manager := testPrincipal(9)
+				manager.Grants = []models.Grant{{Permission: models.PermBannerDelete, FeatureID: 3}}
+
+				return bannerService.DeleteBanner(ctx, 1, manager)
+			},
+		},
+		{
+			name: "author without delete permission deletes own banner",
+			call: func(bannerService *BannerService) error {
+				author := testEditor(3)
+				author.UserID = 1
+
+				return bannerService.DeleteBanner(ctx, 1, author)
+			},
+			wantErr: true,
+		},
+		{
+			name: "editor transfers banner",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.TransferBanner(ctx, 1, 2, testEditor(3), 4)
+
+				return err
+			},
+		},
+		{
+			name: "editor of other feature transfers banner",
+			call: func(bannerService *BannerService) error {
+				_, err := bannerService.TransferBanner(ctx, 1, 2, testEditor(4), 4)
+
+				return err
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &fakeBannerStorage{} //nolint:exhaustruct
+			bannerService := newTestBannerService(t, storage)
+
+			err := tt.call(bannerService)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+
+				return
+			}
+
+			if !isErrKind(err, myerrors.KindForbidden) {
+				t.Errorf("err = %v, want forbidden error", err)
+			}
+
+			if storage.added != nil || storage.updated != nil || storage.deleted || storage.gotAuthorID != 0 ||
+				storage.gotUserID != 0 {
+				t.Error("storage is changed without permission")
+			}
+		})
+	}
+}
+
+func TestBannerServiceAddBannerValidatesFirst(t *testing.T) {
+	t.Parallel()
+
+	storage := &fakeBannerStorage{} //nolint:exhaustruct
+	bannerService := newTestBannerService(t, storage)
+
+	// malformed banner is a bad request even for user without rights on its feature
+	_, err := bannerService.AddBanner(context.Background(),
+		strings.NewReader(`{"tag_ids": [1, 1], "feature_id": 4, "content": {}, "is_active": true}`), testEditor(3))
+	if !errors.Is(err, ErrDuplicateTagIDs) {
+		t.Errorf("err = %v, want %v", err, ErrDuplicateTagIDs)
+	}
+
+	if storage.added != nil {
+		t.Error("malformed banner is added")
+	}
+}
@@ -0,0 +1,129 @@
+package usecases
+
+import (
+	"encoding/json"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"sync"
+	"time"
+)
+
+var _ IBannerCache = (*BannerCache)(nil)
+
+type IBannerCache interface {
+	GetBanner(bannerID uint64, isAdmin bool) (json.RawMessage, uint64, bool)
+	SetBanner(bannerID uint64, isAdmin bool, content json.RawMessage, revision uint64)
+	GetUserBanner(featureID uint64, tagID uint64, isAdmin bool) (json.RawMessage, bool)
+	SetUserBanner(featureID uint64, tagID uint64, isAdmin bool, content json.RawMessage)
+	Invalidate()
+	Stats() models.BannerCacheStats
+}
+
+// bannerCacheKey identifies either a banner by id or a user banner by feature and tag.
+// isAdmin is a part of the key because inactive banners are visible only to admins.
+type bannerCacheKey struct {
+	bannerID  uint64
+	featureID uint64
+	tagID     uint64
+	isAdmin   bool
+}
+
+// bannerCacheEntry keeps revision of banner for ETag, it is not known for user banners.
+type bannerCacheEntry struct {
+	content   json.RawMessage
+	revision  uint64
+	expiresAt time.Time
+}
+
+// BannerCache keeps banners content in memory for ttl. Users who need
+// the latest revision of banner must bypass it. Expired entries are deleted on write
+// at most once per ttl, so entries which are never read again live no longer than two ttl.
+type BannerCache struct {
+	ttl       time.Duration
+	mu        sync.RWMutex
+	entries   map[bannerCacheKey]bannerCacheEntry
+	lastSweep time.Time
+}
+
+func NewBannerCache(ttl time.Duration) *BannerCache {
+	return &BannerCache{
+		ttl:       ttl,
+		mu:        sync.RWMutex{},
+		entries:   make(map[bannerCacheKey]bannerCacheEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *BannerCache) get(key bannerCacheKey) (bannerCacheEntry, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return bannerCacheEntry{}, false //nolint:exhaustruct
+	}
+
+	return entry, true
+}
+
+func (c *BannerCache) set(key bannerCacheKey, content json.RawMessage, revision uint64) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastSweep) >= c.ttl {
+		c.sweep(now)
+	}
+
+	c.entries[key] = bannerCacheEntry{content: content, revision: revision, expiresAt: now.Add(c.ttl)}
+}
+
+// sweep deletes entries expired by now, c.mu must be held.
+func (c *BannerCache) sweep(now time.Time) {
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.lastSweep = now
+}
+
+func (c *BannerCache) GetBanner(bannerID uint64, isAdmin bool) (json.RawMessage, uint64, bool) {
+	entry, ok := c.get(bannerCacheKey{bannerID: bannerID, featureID: 0, tagID: 0, isAdmin: isAdmin})
+
+	return entry.content, entry.revision, ok
+}
+
+func (c *BannerCache) SetBanner(bannerID uint64, isAdmin bool, content json.RawMessage, revision uint64) {
+	c.set(bannerCacheKey{bannerID: bannerID, featureID: 0, tagID: 0, isAdmin: isAdmin}, content, revision)
+}
+
+func (c *BannerCache) GetUserBanner(featureID uint64, tagID uint64, isAdmin bool) (json.RawMessage, bool) {
+	entry, ok := c.get(bannerCacheKey{bannerID: 0, featureID: featureID, tagID: tagID, isAdmin: isAdmin})
+
+	return entry.content, ok
+}
+
+func (c *BannerCache) SetUserBanner(featureID uint64, tagID uint64, isAdmin bool, content json.RawMessage) {
+	c.set(bannerCacheKey{bannerID: 0, featureID: featureID, tagID: tagID, isAdmin: isAdmin}, content, 0)
+}
+
+// Invalidate drops all entries, it is called after banners are changed.
+func (c *BannerCache) Invalidate() {
+	c.mu.Lock()
+	c.entries = make(map[bannerCacheKey]bannerCacheEntry)
+	c.mu.Unlock()
+}
+
+func (c *BannerCache) Stats() models.BannerCacheStats {
+	c.mu.RLock()
+	size := len(c.entries)
+	c.mu.RUnlock()
+
+	return models.BannerCacheStats{ //nolint:exhaustruct
+		Mode: BannerCacheModeTTL,
+		Size: size,
+		TTL:  c.ttl.String(),
+	}
+}
@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBannerCache(t *testing.T) {
+	t.Parallel()
+
+	cache := NewBannerCache(time.Hour)
+
+	if _, _, ok := cache.GetBanner(1, false); ok {
+		t.Fatal("empty cache has banner")
+	}
+
+	cache.SetBanner(1, true, json.RawMessage(`{"for": "admin"}`), 4)
+	cache.SetUserBanner(10, 100, false, json.RawMessage(`{"for": "user"}`))
+
+	content, revision, ok := cache.GetBanner(1, true)
+	if !ok || string(content) != `{"for": "admin"}` || revision != 4 {
+		t.Errorf("GetBanner(1, true) = %s, %d, %t", content, revision, ok)
+	}
+
+	// inactive banners are visible only to admins, so entries of admins are not shared with users
+	if _, _, ok := cache.GetBanner(1, false); ok {
+		t.Error("banner cached for admin is returned to user")
+	}
+
+	if content, ok := cache.GetUserBanner(10, 100, false); !ok || string(content) != `{"for": "user"}` {
+		t.Errorf("GetUserBanner(10, 100, false) = %s, %t", content, ok)
+	}
+
+	if _, ok := cache.GetUserBanner(10, 100, true); ok {
+		t.Error("banner cached for user is returned to admin")
+	}
+
+	if _, ok := cache.GetUserBanner(10, 101, false); ok {
+		t.Error("banner is returned for other tag")
+	}
+
+	stats := cache.Stats()
+	if stats.Mode != BannerCacheModeTTL || stats.Size != 2 || stats.TTL != time.Hour.String() ||
+		stats.RefreshInterval != "" {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	cache.Invalidate()
+
+	if _, _, ok := cache.GetBanner(1, true); ok {
+		t.Error("banner is left after Invalidate")
+	}
+
+	if _, ok := cache.GetUserBanner(10, 100, false); ok {
+		t.Error("user banner is left after Invalidate")
+	}
+
+	if size := cache.Stats().Size; size != 0 {
+		t.Errorf("size after Invalidate = %d, want 0", size)
+	}
+}
+
+func TestBannerCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	const ttl = 20 * time.Millisecond
+
+	cache := NewBannerCache(ttl)
+	cache.SetBanner(1, false, json.RawMessage(`{}`), 1)
+	cache.SetUserBanner(10, 100, false, json.RawMessage(`{}`))
+
+	if _, _, ok := cache.GetBanner(1, false); !ok {
+		t.Fatal("banner is expired before ttl")
+	}
+
+	time.Sleep(2 * ttl)
+
+	if _, _, ok := cache.GetBanner(1, false); ok {
+		t.Error("banner is returned after ttl")
+	}
+
+	if _, ok := cache.GetUserBanner(10, 100, false); ok {
+		t.Error("user banner is returned after ttl")
+	}
+
+	// expired entries which are never read again are deleted on next write
+	cache.SetBanner(2, false, json.RawMessage(`{}`), 1)
+
+	if size := cache.Stats().Size; size != 1 {
+		t.Errorf("size after write = %d, want 1 without expired entries", size)
+	}
+}
+
+func TestBannerServiceUseLastRevision(t *testing.T) {
+	t.Parallel()
+
+	storage := &fakeBannerStorage{ //nolint:exhaustruct
+		banner:         json.RawMessage(`{"revision": 1}`),
+		bannerRevision: 1,
+		userBanner:     json.RawMessage(`{"revision": 1}`),
+	}
+	bannerService := newTestBannerService(t, storage)
+	ctx := context.Background()
+
+	// fill cache with revision 1, then banner is changed in storage
+	if _, _, err := bannerService.GetBanner(ctx, 1, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bannerService.GetUserBanner(ctx, 10, 100, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	storage.banner, storage.bannerRevision = json.RawMessage(`{"revision": 2}`), 2
+	storage.userBanner = json.RawMessage(`{"revision": 2}`)
+
+	tests := []struct {
+		name            string
+		useLastRevision bool
+		wantContent     string
+		wantRevision    uint64
+		wantReads       int
+	}{
+		{
+			name: "cached revision", useLastRevision: false,
+			wantContent: `{"revision": 1}`, wantRevision: 1, wantReads: 0,
+		},
+		{
+			name: "last revision bypasses cache", useLastRevision: true,
+			wantContent: `{"revision": 2}`, wantRevision: 2, wantReads: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		storage.reads = 0
+
+		content, revision, err := bannerService.GetBanner(ctx, 1, false, tt.useLastRevision)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if string(content) != tt.wantContent || revision != tt.wantRevision || storage.reads != tt.wantReads {
+			t.Errorf("%s: GetBanner() = %s, revision %d with %d reads of storage, want %s, revision %d with %d",
+				tt.name, content, revision, storage.reads, tt.wantContent, tt.wantRevision, tt.wantReads)
+		}
+
+		storage.reads = 0
+
+		content, err = bannerService.GetUserBanner(ctx, 10, 100, false, tt.useLastRevision)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if string(content) != tt.wantContent || storage.reads != tt.wantReads {
+			t.Errorf("%s: GetUserBanner() = %s with %d reads of storage, want %s with %d",
+				tt.name, content, storage.reads, tt.wantContent, tt.wantReads)
+		}
+	}
+
+	// the last revision read from storage is cached for next readers
+	storage.reads = 0
+
+	if content, _, _ := bannerService.GetBanner(ctx, 1, false, false); string(content) != `{"revision": 2}` ||
+		storage.reads != 0 {
+		t.Errorf("content after bypass = %s with %d reads of storage, want revision 2 from cache",
+			content, storage.reads)
+	}
+}
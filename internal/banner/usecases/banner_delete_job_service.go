@@ -0,0 +1,53 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+)
+
+var (
+	ErrDeleteJobFeatureOrTag = myerrors.NewError("delete_job_feature_or_tag", myerrors.Message{
+		RU: "Для удаления баннеров нужно указать либо feature_id, либо tag_id",
+		EN: "Either feature_id or tag_id must be given to delete banners",
+	})
+)
+
+// AddBannerDeleteJob records deletion of all banners of feature or of tag and returns
+// id of job at once, banners are deleted by BannerDeleteWorker. Principal must have banner:delete
+// on feature, banners of tag can be deleted only with banner:delete on all features.
+func (b *BannerService) AddBannerDeleteJob(ctx context.Context, featureID uint64, tagID uint64,
+	principal *models.Principal) (uint64, error) {
+	if (featureID == 0) == (tagID == 0) {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, ErrDeleteJobFeatureOrTag)
+	}
+
+	if err := Authorize(principal, models.PermBannerDelete, featureID); err != nil {
+		return 0, err
+	}
+
+	jobID, err := b.storage.AddBannerDeleteJob(ctx, featureID, tagID, principal.UserID)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	b.deleteWorker.Wake()
+
+	return jobID, nil
+}
+
+// GetBannerDeleteJob returns job to principal, which could add it.
+func (b *BannerService) GetBannerDeleteJob(ctx context.Context, jobID uint64,
+	principal *models.Principal) (*models.BannerDeleteJob, error) {
+	job, err := b.storage.GetBannerDeleteJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if err := Authorize(principal, models.PermBannerDelete, job.FeatureID); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,65 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+func TestBannerServiceAddBannerDeleteJob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		featureID uint64
+		tagID     uint64
+		wantErr   error
+	}{
+		{name: "by feature", featureID: 3},
+		{name: "by tag", tagID: 7},
+		{name: "neither feature nor tag", wantErr: ErrDeleteJobFeatureOrTag},
+		{name: "both feature and tag", featureID: 3, tagID: 7, wantErr: ErrDeleteJobFeatureOrTag},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &fakeBannerStorage{} //nolint:exhaustruct
+			deleteWorker := &fakeBannerDeleteWorker{}
+
+			bannerService, err := NewBannerService(storage, NewBannerCache(time.Hour), deleteWorker)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			jobID, err := bannerService.AddBannerDeleteJob(context.Background(), tt.featureID, tt.tagID,
+				testPrincipal(5, models.RoleAdmin))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				if deleteWorker.wakes != 0 || storage.gotUserID != 0 {
+					t.Error("job is added for bad request")
+				}
+
+				return
+			}
+
+			if jobID != 1 || storage.gotFeatureID != tt.featureID || storage.gotTagID != tt.tagID ||
+				storage.gotUserID != 5 {
+				t.Errorf("job %d is added for feature %d, tag %d by user %d",
+					jobID, storage.gotFeatureID, storage.gotTagID, storage.gotUserID)
+			}
+
+			if deleteWorker.wakes != 1 {
+				t.Errorf("worker is woken %d times, want 1", deleteWorker.wakes)
+			}
+		})
+	}
+}
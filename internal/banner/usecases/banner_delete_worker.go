@@ -0,0 +1,194 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	bannerrepo "github.com/SanExpett/banners-backend/internal/banner/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/SanExpett/banners-backend/pkg/utils"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// bannerDeleteJobLease is how long job is held by worker without renewal, it must be
+	// much longer than deletion of one batch
+	bannerDeleteJobLease = time.Minute
+	// job failed with transient error is retried after attempts * bannerDeleteJobRetryDelay,
+	// it fails for good after bannerDeleteJobMaxAttempts
+	bannerDeleteJobRetryDelay  = 10 * time.Second
+	bannerDeleteJobMaxAttempts = 5
+	workerIDLen                = 8
+)
+
+var (
+	_ IBannerDeleteWorker     = (*BannerDeleteWorker)(nil)
+	_ IBannerDeleteJobStorage = (*bannerrepo.BannerStorage)(nil)
+)
+
+type IBannerDeleteWorker interface {
+	Wake()
+}
+
+type IBannerDeleteJobStorage interface {
+	TakeBannerDeleteJob(ctx context.Context, workerID string, lease time.Duration) (*models.BannerDeleteJob, error)
+	DeleteBannersBatch(ctx context.Context, job *models.BannerDeleteJob, workerID string, lease time.Duration,
+		batchSize uint64) (uint64, bool, error)
+	FinishBannerDeleteJob(ctx context.Context, jobID uint64, workerID string, errMessage string) error
+	ReleaseBannerDeleteJob(ctx context.Context, jobID uint64, workerID string, errMessage string,
+		retryAfter time.Duration) error
+}
+
+// BannerDeleteWorker performs banner delete jobs in batches, so no long transaction
+// locks banners. Jobs are looked for every pollInterval and right after Wake. Job is held
+// under lease of worker with id, so workers of several instances of service never run it together.
+type BannerDeleteWorker struct {
+	id           string
+	storage      IBannerDeleteJobStorage
+	cache        IBannerCache
+	batchSize    uint64
+	pollInterval time.Duration
+	wakeCh       chan struct{}
+	logger       *zap.SugaredLogger
+}
+
+func NewBannerDeleteWorker(storage IBannerDeleteJobStorage, cache IBannerCache, batchSize uint64,
+	pollInterval time.Duration) (*BannerDeleteWorker, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	id, err := utils.RandomString(workerIDLen)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &BannerDeleteWorker{
+		id:           id,
+		storage:      storage,
+		cache:        cache,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		wakeCh:       make(chan struct{}, 1),
+		logger:       logger,
+	}, nil
+}
+
+// Run performs jobs until ctx is done.
+func (w *BannerDeleteWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.runJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wakeCh:
+		}
+	}
+}
+
+// Wake makes worker look for new jobs without waiting for it.
+func (w *BannerDeleteWorker) Wake() {
+	select {
+	case w.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *BannerDeleteWorker) runJobs(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.storage.TakeBannerDeleteJob(ctx, w.id, bannerDeleteJobLease)
+		if err != nil {
+			w.logger.Errorf("in BannerDeleteWorker.runJobs: %+v", err)
+
+			return
+		}
+
+		if job == nil {
+			return
+		}
+
+		w.runJob(ctx, job)
+	}
+}
+
+func (w *BannerDeleteWorker) runJob(ctx context.Context, job *models.BannerDeleteJob) {
+	for {
+		deletedCount, bannersLeft, err := w.storage.DeleteBannersBatch(ctx, job, w.id, bannerDeleteJobLease,
+			w.batchSize)
+		if err != nil {
+			// job stays running and is continued after restart or by another worker
+			if ctx.Err() != nil || errors.Is(err, bannerrepo.ErrBannerDeleteJobLeaseLost) {
+				w.logger.Warnf("in BannerDeleteWorker.runJob: job id=%d is left: %+v", job.ID, err)
+
+				return
+			}
+
+			w.retryOrFailJob(ctx, job, err)
+
+			return
+		}
+
+		if deletedCount > 0 {
+			w.cache.Invalidate()
+		}
+
+		if !bannersLeft {
+			w.finishJob(ctx, job.ID, "")
+
+			return
+		}
+
+		// all banners left are locked by other transactions, wait for them instead of spinning
+		if deletedCount == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.pollInterval):
+			}
+		}
+	}
+}
+
+// retryOrFailJob releases job after transient jobErr, so it is retried later by any worker,
+// and fails it after any other error or when attempts are exhausted.
+func (w *BannerDeleteWorker) retryOrFailJob(ctx context.Context, job *models.BannerDeleteJob, jobErr error) {
+	attempts := job.Attempts + 1
+
+	if !errors.Is(jobErr, bannerrepo.ErrBannerDeleteBatchTransient) || attempts >= bannerDeleteJobMaxAttempts {
+		w.finishJob(ctx, job.ID, jobErr.Error())
+
+		return
+	}
+
+	retryAfter := time.Duration(attempts) * bannerDeleteJobRetryDelay
+
+	err := w.storage.ReleaseBannerDeleteJob(ctx, job.ID, w.id, jobErr.Error(), retryAfter)
+	if err != nil {
+		w.logger.Errorf("in BannerDeleteWorker.retryOrFailJob: jobID=%d: %+v", job.ID, err)
+
+		return
+	}
+
+	w.logger.Warnf("in BannerDeleteWorker.retryOrFailJob: job id=%d is retried in %s after attempt %d: %+v",
+		job.ID, retryAfter, attempts, jobErr)
+}
+
+func (w *BannerDeleteWorker) finishJob(ctx context.Context, jobID uint64, errMessage string) {
+	err := w.storage.FinishBannerDeleteJob(ctx, jobID, w.id, errMessage)
+	if err != nil {
+		w.logger.Errorf("in BannerDeleteWorker.finishJob: jobID=%d: %+v", jobID, err)
+
+		return
+	}
+
+	w.logger.Infof("in BannerDeleteWorker.finishJob: finished job id=%d error=%q", jobID, errMessage)
+}
@@ -0,0 +1,254 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	bannerrepo "github.com/SanExpett/banners-backend/internal/banner/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+// fakeBannerDeleteJobStorage holds queue of jobs and count of banners left for every job.
+type fakeBannerDeleteJobStorage struct {
+	jobs        []*models.BannerDeleteJob
+	bannersLeft map[uint64]uint64
+	// failAt is the number of DeleteBannersBatch call that fails with failErr, 0 means no failures
+	failAt   int
+	failErr  error
+	batches  int
+	finished map[uint64]string
+	// released are errors of released jobs, retryAfter is the delay of the last one
+	released   map[uint64]string
+	retryAfter time.Duration
+	// workerIDs are ids of workers which held jobs
+	workerIDs map[string]bool
+}
+
+func (s *fakeBannerDeleteJobStorage) TakeBannerDeleteJob(_ context.Context, workerID string,
+	_ time.Duration) (*models.BannerDeleteJob, error) {
+	s.workerIDs[workerID] = true
+
+	if len(s.jobs) == 0 {
+		return nil, nil
+	}
+
+	job := s.jobs[0]
+	s.jobs = s.jobs[1:]
+
+	return job, nil
+}
+
+func (s *fakeBannerDeleteJobStorage) DeleteBannersBatch(_ context.Context, job *models.BannerDeleteJob,
+	workerID string, _ time.Duration, batchSize uint64) (uint64, bool, error) {
+	s.workerIDs[workerID] = true
+
+	s.batches++
+	if s.batches == s.failAt {
+		return 0, false, s.failErr
+	}
+
+	deletedCount := min(batchSize, s.bannersLeft[job.ID])
+	s.bannersLeft[job.ID] -= deletedCount
+
+	return deletedCount, s.bannersLeft[job.ID] > 0, nil
+}
+
+func (s *fakeBannerDeleteJobStorage) FinishBannerDeleteJob(_ context.Context, jobID uint64, workerID string,
+	errMessage string) error {
+	s.workerIDs[workerID] = true
+	s.finished[jobID] = errMessage
+
+	return nil
+}
+
+func (s *fakeBannerDeleteJobStorage) ReleaseBannerDeleteJob(_ context.Context, jobID uint64, workerID string,
+	errMessage string, retryAfter time.Duration) error {
+	s.workerIDs[workerID] = true
+	s.released[jobID], s.retryAfter = errMessage, retryAfter
+
+	return nil
+}
+
+func TestBannerDeleteWorker(t *testing.T) {
+	t.Parallel()
+
+	const batchSize = 10
+
+	tests := []struct {
+		name            string
+		bannersLeft     uint64
+		failAt          int
+		failErr         error
+		wantBatches     int
+		wantFinished    bool
+		wantError       string
+		wantLeft        uint64
+		wantInvalidated bool
+	}{
+		{name: "no banners", bannersLeft: 0, wantBatches: 1, wantFinished: true},
+		{name: "less than batch", bannersLeft: 5, wantBatches: 1, wantFinished: true, wantInvalidated: true},
+		{name: "exactly batches", bannersLeft: 20, wantBatches: 2, wantFinished: true, wantInvalidated: true},
+		{name: "several batches", bannersLeft: 25, wantBatches: 3, wantFinished: true, wantInvalidated: true},
+		{
+			name: "failed batch", bannersLeft: 25, failAt: 2, failErr: errStorageDown, wantBatches: 2,
+			wantFinished: true, wantError: errStorageDown.Error(), wantLeft: 15, wantInvalidated: true,
+		},
+		// job held by another worker is left to it
+		{
+			name: "lease lost", bannersLeft: 25, failAt: 2, failErr: bannerrepo.ErrBannerDeleteJobLeaseLost,
+			wantBatches: 2, wantFinished: false, wantLeft: 15, wantInvalidated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &fakeBannerDeleteJobStorage{
+				jobs:        []*models.BannerDeleteJob{{ID: 1, FeatureID: 3}}, //nolint:exhaustruct
+				bannersLeft: map[uint64]uint64{1: tt.bannersLeft},
+				failAt:      tt.failAt,
+				failErr:     tt.failErr,
+				batches:     0,
+				finished:    make(map[uint64]string),
+				released:    make(map[uint64]string),
+				workerIDs:   make(map[string]bool),
+			}
+
+			cache := NewBannerCache(time.Hour)
+			cache.SetBanner(1, false, json.RawMessage(`{}`), 1)
+
+			worker, err := NewBannerDeleteWorker(storage, cache, batchSize, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			worker.runJobs(context.Background())
+
+			if storage.batches != tt.wantBatches {
+				t.Errorf("batches = %d, want %d", storage.batches, tt.wantBatches)
+			}
+
+			if errMessage, ok := storage.finished[1]; ok != tt.wantFinished || errMessage != tt.wantError {
+				t.Errorf("job is finished = %t with error %q, want %t with error %q",
+					ok, errMessage, tt.wantFinished, tt.wantError)
+			}
+
+			if len(storage.workerIDs) != 1 || !storage.workerIDs[worker.id] {
+				t.Errorf("job is held by workers %v, want only %q", storage.workerIDs, worker.id)
+			}
+
+			if storage.bannersLeft[1] != tt.wantLeft {
+				t.Errorf("banners left = %d, want %d", storage.bannersLeft[1], tt.wantLeft)
+			}
+
+			// cache is dropped only when banners were deleted
+			if _, _, ok := cache.GetBanner(1, false); ok == tt.wantInvalidated {
+				t.Errorf("cache is kept = %t after %d deleted banners", ok, tt.bannersLeft-storage.bannersLeft[1])
+			}
+		})
+	}
+}
+
+func TestBannerDeleteWorkerRunsEveryJob(t *testing.T) {
+	t.Parallel()
+
+	storage := &fakeBannerDeleteJobStorage{
+		jobs: []*models.BannerDeleteJob{
+			{ID: 1, FeatureID: 3}, //nolint:exhaustruct
+			{ID: 2, TagID: 7},     //nolint:exhaustruct
+		},
+		bannersLeft: map[uint64]uint64{1: 4, 2: 12},
+		failAt:      0,
+		failErr:     nil,
+		batches:     0,
+		finished:    make(map[uint64]string),
+		released:    make(map[uint64]string),
+		workerIDs:   make(map[string]bool),
+	}
+
+	worker, err := NewBannerDeleteWorker(storage, NewBannerCache(time.Hour), 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker.runJobs(context.Background())
+
+	if len(storage.finished) != 2 || storage.bannersLeft[1] != 0 || storage.bannersLeft[2] != 0 {
+		t.Errorf("finished jobs = %v, banners left = %v", storage.finished, storage.bannersLeft)
+	}
+}
+
+func TestBannerDeleteWorkerRetries(t *testing.T) {
+	t.Parallel()
+
+	transientErr := fmt.Errorf("%w: %w", bannerrepo.ErrBannerDeleteBatchTransient, errStorageDown)
+
+	tests := []struct {
+		name           string
+		failErr        error
+		attempts       uint64
+		wantReleased   bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			name: "transient error", failErr: transientErr, attempts: 0,
+			wantReleased: true, wantRetryAfter: bannerDeleteJobRetryDelay,
+		},
+		{
+			name: "transient error again", failErr: transientErr, attempts: 2,
+			wantReleased: true, wantRetryAfter: 3 * bannerDeleteJobRetryDelay,
+		},
+		{name: "attempts exhausted", failErr: transientErr, attempts: bannerDeleteJobMaxAttempts - 1},
+		{name: "not transient error", failErr: errStorageDown, attempts: 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &fakeBannerDeleteJobStorage{
+				jobs:        []*models.BannerDeleteJob{{ID: 1, FeatureID: 3, Attempts: tt.attempts}}, //nolint:exhaustruct
+				bannersLeft: map[uint64]uint64{1: 25},
+				failAt:      1,
+				failErr:     tt.failErr,
+				batches:     0,
+				finished:    make(map[uint64]string),
+				released:    make(map[uint64]string),
+				retryAfter:  0,
+				workerIDs:   make(map[string]bool),
+			}
+
+			worker, err := NewBannerDeleteWorker(storage, NewBannerCache(time.Hour), 10, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			worker.runJobs(context.Background())
+
+			errMessage, released := storage.released[1]
+			if released != tt.wantReleased || storage.retryAfter != tt.wantRetryAfter {
+				t.Fatalf("job is released = %t for %s, want %t for %s",
+					released, storage.retryAfter, tt.wantReleased, tt.wantRetryAfter)
+			}
+
+			if !released {
+				errMessage = storage.finished[1]
+			}
+
+			if errMessage != tt.failErr.Error() {
+				t.Errorf("error of job = %q, want %q", errMessage, tt.failErr.Error())
+			}
+
+			if _, finished := storage.finished[1]; finished == released {
+				t.Errorf("job is finished = %t and released = %t", finished, released)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	bannerrepo "github.com/SanExpett/banners-backend/internal/banner/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	BannerCacheModeTTL   = "ttl"
+	BannerCacheModeIndex = "index"
+)
+
+var (
+	_ IBannerCache        = (*BannerIndex)(nil)
+	_ IBannerIndexStorage = (*bannerrepo.BannerStorage)(nil)
+)
+
+type IBannerIndexStorage interface {
+	GetAllBanners(ctx context.Context) ([]*models.Banner, error)
+}
+
+type featureTagKey struct {
+	featureID uint64
+	tagID     uint64
+}
+
+type bannerIndexEntry struct {
+	content  json.RawMessage
+	revision uint64
+	isActive bool
+}
+
+// bannerIndexSnapshot is never changed after it is built, so it is read without locks.
+type bannerIndexSnapshot struct {
+	byID         map[uint64]bannerIndexEntry
+	byFeatureTag map[featureTagKey]bannerIndexEntry
+	builtAt      time.Time
+}
+
+// BannerIndex keeps a complete in-memory copy of banners content. It is rebuilt
+// from storage every refreshInterval and swapped atomically. Lookups that miss
+// the index (e.g. banner was created after the last build) fall through to storage.
+type BannerIndex struct {
+	storage         IBannerIndexStorage
+	refreshInterval time.Duration
+	snapshot        atomic.Pointer[bannerIndexSnapshot]
+	refreshCh       chan struct{}
+	logger          *zap.SugaredLogger
+}
+
+func NewBannerIndex(storage IBannerIndexStorage, refreshInterval time.Duration) (*BannerIndex, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &BannerIndex{ //nolint:exhaustruct
+		storage:         storage,
+		refreshInterval: refreshInterval,
+		refreshCh:       make(chan struct{}, 1),
+		logger:          logger,
+	}, nil
+}
+
+// Run rebuilds the index until ctx is done.
+func (i *BannerIndex) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := i.Refresh(ctx); err != nil {
+			i.logger.Errorf("in BannerIndex.Run: refresh failed, last build at %s: %+v",
+				i.Stats().LastBuildAt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-i.refreshCh:
+		}
+	}
+}
+
+func (i *BannerIndex) Refresh(ctx context.Context) error {
+	banners, err := i.storage.GetAllBanners(ctx)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	snapshot := &bannerIndexSnapshot{
+		byID:         make(map[uint64]bannerIndexEntry, len(banners)),
+		byFeatureTag: make(map[featureTagKey]bannerIndexEntry, len(banners)),
+		builtAt:      time.Now(),
+	}
+
+	for _, banner := range banners {
+		entry := bannerIndexEntry{content: banner.Content, revision: banner.Revision, isActive: banner.IsActive}
+
+		snapshot.byID[banner.BannerID] = entry
+		for _, tagID := range banner.TagIDs {
+			snapshot.byFeatureTag[featureTagKey{featureID: banner.FeatureID, tagID: tagID}] = entry
+		}
+	}
+
+	i.snapshot.Store(snapshot)
+	i.logger.Infof("in BannerIndex.Refresh: built index of %d banners", len(snapshot.byID))
+
+	return nil
+}
+
+func (i *BannerIndex) lookup(entry bannerIndexEntry, ok bool, isAdmin bool) (bannerIndexEntry, bool) {
+	// inactive banner is a miss for user, so storage reports the proper error
+	if !ok || (!entry.isActive && !isAdmin) {
+		return bannerIndexEntry{}, false //nolint:exhaustruct
+	}
+
+	return entry, true
+}
+
+func (i *BannerIndex) GetBanner(bannerID uint64, isAdmin bool) (json.RawMessage, uint64, bool) {
+	snapshot := i.snapshot.Load()
+	if snapshot == nil {
+		return nil, 0, false
+	}
+
+	entry, ok := snapshot.byID[bannerID]
+	entry, ok = i.lookup(entry, ok, isAdmin)
+
+	return entry.content, entry.revision, ok
+}
+
+func (i *BannerIndex) GetUserBanner(featureID uint64, tagID uint64, isAdmin bool) (json.RawMessage, bool) {
+	snapshot := i.snapshot.Load()
+	if snapshot == nil {
+		return nil, false
+	}
+
+	entry, ok := snapshot.byFeatureTag[featureTagKey{featureID: featureID, tagID: tagID}]
+	entry, ok = i.lookup(entry, ok, isAdmin)
+
+	return entry.content, ok
+}
+
+// SetBanner does nothing, the index is filled only by Refresh.
+func (i *BannerIndex) SetBanner(uint64, bool, json.RawMessage, uint64) {}
+
+// SetUserBanner does nothing, the index is filled only by Refresh.
+func (i *BannerIndex) SetUserBanner(uint64, uint64, bool, json.RawMessage) {}
+
+// Invalidate schedules rebuilding of the index without waiting for it.
+func (i *BannerIndex) Invalidate() {
+	select {
+	case i.refreshCh <- struct{}{}:
+	default:
+	}
+}
+
+func (i *BannerIndex) Stats() models.BannerCacheStats {
+	stats := models.BannerCacheStats{ //nolint:exhaustruct
+		Mode:            BannerCacheModeIndex,
+		RefreshInterval: i.refreshInterval.String(),
+	}
+
+	if snapshot := i.snapshot.Load(); snapshot != nil {
+		stats.Size = len(snapshot.byID)
+		stats.LastBuildAt = snapshot.builtAt
+	}
+
+	return stats
+}
@@ -0,0 +1,302 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBannerIndexStorage returns the banners it holds, or err if it is set.
+type fakeBannerIndexStorage struct {
+	mu      sync.Mutex
+	banners []*models.Banner
+	err     error
+	calls   atomic.Int64
+}
+
+func (s *fakeBannerIndexStorage) set(banners []*models.Banner, err error) {
+	s.mu.Lock()
+	s.banners, s.err = banners, err
+	s.mu.Unlock()
+}
+
+func (s *fakeBannerIndexStorage) GetAllBanners(context.Context) ([]*models.Banner, error) {
+	s.calls.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.banners, s.err
+}
+
+func indexedBannerContent(version uint64) string {
+	return fmt.Sprintf(`{"version": %d}`, version)
+}
+
+func indexedBanner(bannerID, featureID uint64, tagIDs []uint64, isActive bool, version uint64) *models.Banner {
+	return &models.Banner{ //nolint:exhaustruct
+		BannerID:  bannerID,
+		TagIDs:    tagIDs,
+		FeatureID: featureID,
+		Content:   json.RawMessage(indexedBannerContent(version)),
+		IsActive:  isActive,
+		Revision:  version,
+	}
+}
+
+func newTestBannerIndex(t *testing.T, banners ...*models.Banner) (*BannerIndex, *fakeBannerIndexStorage) {
+	t.Helper()
+
+	storage := &fakeBannerIndexStorage{} //nolint:exhaustruct
+	storage.set(banners, nil)
+
+	index, err := NewBannerIndex(storage, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return index, storage
+}
+
+func TestBannerIndexLookups(t *testing.T) {
+	t.Parallel()
+
+	index, _ := newTestBannerIndex(t,
+		indexedBanner(1, 10, []uint64{100, 101}, true, 3),
+		indexedBanner(2, 20, []uint64{200}, false, 5),
+	)
+
+	if _, _, ok := index.GetBanner(1, false); ok {
+		t.Fatal("index is used before first Refresh")
+	}
+
+	if err := index.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		bannerID    uint64
+		isAdmin     bool
+		wantOK      bool
+		wantVersion uint64
+	}{
+		{name: "active banner for user", bannerID: 1, isAdmin: false, wantOK: true, wantVersion: 3},
+		{name: "active banner for admin", bannerID: 1, isAdmin: true, wantOK: true, wantVersion: 3},
+		{name: "inactive banner for user", bannerID: 2, isAdmin: false, wantOK: false},
+		{name: "inactive banner for admin", bannerID: 2, isAdmin: true, wantOK: true, wantVersion: 5},
+		{name: "unknown banner", bannerID: 3, isAdmin: true, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, revision, ok := index.GetBanner(tt.bannerID, tt.isAdmin)
+			if ok != tt.wantOK {
+				t.Fatalf("GetBanner() found = %t, want %t", ok, tt.wantOK)
+			}
+
+			if want := indexedBannerContent(tt.wantVersion); ok && string(content) != want {
+				t.Errorf("content = %s, want %s", content, want)
+			}
+
+			if ok && revision != tt.wantVersion {
+				t.Errorf("revision = %d, want %d", revision, tt.wantVersion)
+			}
+		})
+	}
+
+	t.Run("user banner by every tag", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tagID := range []uint64{100, 101} {
+			if content, ok := index.GetUserBanner(10, tagID, false); !ok || string(content) != indexedBannerContent(3) {
+				t.Errorf("GetUserBanner(10, %d) = %s, %t", tagID, content, ok)
+			}
+		}
+
+		if _, ok := index.GetUserBanner(20, 200, false); ok {
+			t.Error("inactive user banner is found for user")
+		}
+
+		if _, ok := index.GetUserBanner(10, 200, true); ok {
+			t.Error("banner is found by tag of other feature")
+		}
+	})
+}
+
+func TestBannerIndexRefresh(t *testing.T) {
+	t.Parallel()
+
+	index, storage := newTestBannerIndex(t, indexedBanner(1, 10, []uint64{100}, true, 1))
+
+	if err := index.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	firstBuildAt := index.Stats().LastBuildAt
+
+	// banner 1 is deleted and banner 2 is created
+	storage.set([]*models.Banner{indexedBanner(2, 10, []uint64{100}, true, 1)}, nil)
+
+	if err := index.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, ok := index.GetBanner(1, true); ok {
+		t.Error("deleted banner is left in index")
+	}
+
+	if _, _, ok := index.GetBanner(2, true); !ok {
+		t.Error("new banner is not in index")
+	}
+
+	stats := index.Stats()
+	if stats.Mode != BannerCacheModeIndex || stats.Size != 1 || stats.RefreshInterval != time.Hour.String() {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	if stats.LastBuildAt.Before(firstBuildAt) {
+		t.Errorf("LastBuildAt = %s, want not before %s", stats.LastBuildAt, firstBuildAt)
+	}
+
+	// failed refresh keeps the last built index
+	storage.set(nil, errStorageDown)
+
+	if err := index.Refresh(context.Background()); !errors.Is(err, errStorageDown) {
+		t.Fatalf("err = %v, want %v", err, errStorageDown)
+	}
+
+	if _, _, ok := index.GetBanner(2, true); !ok {
+		t.Error("index is dropped after failed refresh")
+	}
+
+	if got := index.Stats().LastBuildAt; !got.Equal(stats.LastBuildAt) {
+		t.Errorf("LastBuildAt = %s after failed refresh, want %s", got, stats.LastBuildAt)
+	}
+}
+
+// TestBannerIndexSwap checks that readers never see a partially built index
+// and never go back to an older one while the index is rebuilt.
+func TestBannerIndexSwap(t *testing.T) {
+	t.Parallel()
+
+	const (
+		bannersCount = 50
+		refreshes    = 200
+	)
+
+	bannersOfVersion := func(version uint64) []*models.Banner {
+		banners := make([]*models.Banner, 0, bannersCount)
+		for bannerID := uint64(1); bannerID <= bannersCount; bannerID++ {
+			banners = append(banners, indexedBanner(bannerID, 10, []uint64{bannerID}, true, version))
+		}
+
+		return banners
+	}
+
+	index, storage := newTestBannerIndex(t, bannersOfVersion(1)...)
+
+	if err := index.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+
+	var wg sync.WaitGroup
+
+	for reader := 0; reader < 4; reader++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			var lastVersion uint64
+
+			for bannerID := uint64(1); ; bannerID = bannerID%bannersCount + 1 {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				var version uint64
+
+				content, _, ok := index.GetBanner(bannerID, false)
+				if _, err := fmt.Sscanf(string(content), `{"version": %d}`, &version); !ok || err != nil ||
+					version < lastVersion {
+					select {
+					case errs <- fmt.Errorf("GetBanner(%d) = %s, %t after version %d", bannerID, content, ok,
+						lastVersion):
+					default:
+					}
+
+					return
+				}
+
+				lastVersion = version
+			}
+		}()
+	}
+
+	for version := uint64(2); version <= refreshes; version++ {
+		storage.set(bannersOfVersion(version), nil)
+
+		if err := index.Refresh(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		t.Fatal(err)
+	default:
+	}
+
+	if content, _, _ := index.GetBanner(1, false); string(content) != indexedBannerContent(refreshes) {
+		t.Errorf("content = %s, want %s", content, indexedBannerContent(refreshes))
+	}
+}
+
+func TestBannerIndexInvalidate(t *testing.T) {
+	t.Parallel()
+
+	index, storage := newTestBannerIndex(t, indexedBanner(1, 10, []uint64{100}, true, 1))
+
+	// Invalidate must not block even if nobody rebuilds the index
+	for call := 0; call < 3; call++ {
+		index.Invalidate()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go index.Run(ctx)
+
+	// first build on start and one more for pending Invalidate
+	deadline := time.Now().Add(5 * time.Second)
+	for storage.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("index is rebuilt %d times, want 2", storage.calls.Load())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, _, ok := index.GetBanner(1, false); !ok {
+		t.Error("banner is not in index after Run")
+	}
+}
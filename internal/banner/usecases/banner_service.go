@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	bannerrepo "github.com/SanExpett/banners-backend/internal/banner/repository"
 	"github.com/SanExpett/banners-backend/pkg/models"
@@ -15,34 +16,66 @@ var _ IBannerStorage = (*bannerrepo.BannerStorage)(nil)
 
 type IBannerStorage interface {
 	AddBanner(ctx context.Context, preBanner *models.PreBanner, userID uint64) (uint64, error)
-	GetBanner(ctx context.Context, bannerID uint64, isAdmin bool) (*models.Content, error)
+	GetBanner(ctx context.Context, bannerID uint64, isAdmin bool) (json.RawMessage, uint64, error)
+	GetBannerByID(ctx context.Context, bannerID uint64) (*models.Banner, error)
+	GetUserBanner(ctx context.Context, featureID uint64, tagID uint64, isAdmin bool) (json.RawMessage, error)
 	GetBannersList(ctx context.Context, featureID uint64, tagID uint64, limit uint64,
 		offset uint64) ([]*models.Banner, error)
-	UpdateBanner(ctx context.Context, newBanner *models.PreBanner, bannerID uint64, userID uint64) error
-	DeleteBanner(ctx context.Context, bannerID uint64, userID uint64) error
+	UpdateBanner(ctx context.Context, newBanner *models.PreBanner, bannerID uint64, userID uint64,
+		expectedRevision uint64) (uint64, error)
+	DeleteBanner(ctx context.Context, bannerID uint64) error
+	SetBannerAuthor(ctx context.Context, bannerID uint64, authorID uint64, userID uint64,
+		expectedRevision uint64) (uint64, error)
+	CheckBannerReferences(ctx context.Context, featureID uint64, tagIDs []uint64) error
+	AddFeatureSchema(ctx context.Context, featureID uint64, schema json.RawMessage, userID uint64) (uint64, error)
+	GetFeatureSchema(ctx context.Context, featureID uint64, version uint64) (*models.FeatureSchema, error)
+	GetFeatureSchemasList(ctx context.Context, featureID uint64) ([]*models.FeatureSchema, error)
+	GetBannersContentByFeature(ctx context.Context, featureID uint64) ([]*models.Banner, error)
+	GetAllBanners(ctx context.Context) ([]*models.Banner, error)
+	GetBannerVersions(ctx context.Context, bannerID uint64) ([]*models.BannerVersion, error)
+	GetBannerVersion(ctx context.Context, bannerID uint64, version uint64) (*models.BannerVersion, error)
+	RestoreBannerVersion(ctx context.Context, bannerID uint64, version uint64, userID uint64,
+		expectedRevision uint64) (uint64, error)
+	AddBannerDeleteJob(ctx context.Context, featureID uint64, tagID uint64, userID uint64) (uint64, error)
+	GetBannerDeleteJob(ctx context.Context, jobID uint64) (*models.BannerDeleteJob, error)
 }
 
 type BannerService struct {
-	storage IBannerStorage
-	logger  *zap.SugaredLogger
+	storage      IBannerStorage
+	cache        IBannerCache
+	deleteWorker IBannerDeleteWorker
+	logger       *zap.SugaredLogger
 }
 
-func NewBannerService(bannerStorage IBannerStorage) (*BannerService, error) {
+func NewBannerService(bannerStorage IBannerStorage, bannerCache IBannerCache,
+	deleteWorker IBannerDeleteWorker) (*BannerService, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return &BannerService{storage: bannerStorage, logger: logger}, nil
+	return &BannerService{storage: bannerStorage, cache: bannerCache, deleteWorker: deleteWorker, logger: logger}, nil
 }
 
-func (b *BannerService) AddBanner(ctx context.Context, r io.Reader, userID uint64) (uint64, error) {
-	preBanner, err := ValidatePreBanner(r)
+// AddBanner adds banner on behalf of principal, which must have banner:write on its feature.
+// Banner is validated before principal is authorized, so malformed request is answered
+// with 400 whatever rights principal has.
+func (b *BannerService) AddBanner(ctx context.Context, r io.Reader, principal *models.Principal) (uint64, error) {
+	preBanner, err := ValidatePreBanner(r, b.contentSchemaGetter(ctx))
 	if err != nil {
 		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	bannerID, err := b.storage.AddBanner(ctx, preBanner, userID)
+	if err := Authorize(principal, models.PermBannerWrite, preBanner.FeatureID); err != nil {
+		return 0, err
+	}
+
+	err = b.storage.CheckBannerReferences(ctx, preBanner.FeatureID, preBanner.TagIDs)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	bannerID, err := b.storage.AddBanner(ctx, preBanner, principal.UserID)
 	if err != nil {
 		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
@@ -50,50 +83,159 @@ func (b *BannerService) AddBanner(ctx context.Context, r io.Reader, userID uint6
 	return bannerID, nil
 }
 
-func (b *BannerService) GetBanner(ctx context.Context, bannerID uint64, isAdmin bool) (*models.Content, error) {
-	banner, err := b.storage.GetBanner(ctx, bannerID, isAdmin)
+// GetBanner returns banner content from cache unless useLastRevision is set.
+func (b *BannerService) GetBanner(ctx context.Context, bannerID uint64, isAdmin bool,
+	useLastRevision bool) (json.RawMessage, uint64, error) {
+	if !useLastRevision {
+		if bannerContent, revision, ok := b.cache.GetBanner(bannerID, isAdmin); ok {
+			return bannerContent, revision, nil
+		}
+	}
+
+	bannerContent, revision, err := b.storage.GetBanner(ctx, bannerID, isAdmin)
+	if err != nil {
+		return nil, 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	b.cache.SetBanner(bannerID, isAdmin, bannerContent, revision)
+
+	return bannerContent, revision, nil
+}
+
+// GetUserBanner returns banner content from cache unless useLastRevision is set.
+func (b *BannerService) GetUserBanner(ctx context.Context, featureID uint64, tagID uint64,
+	isAdmin bool, useLastRevision bool) (json.RawMessage, error) {
+	if !useLastRevision {
+		if bannerContent, ok := b.cache.GetUserBanner(featureID, tagID, isAdmin); ok {
+			return bannerContent, nil
+		}
+	}
+
+	bannerContent, err := b.storage.GetUserBanner(ctx, featureID, tagID, isAdmin)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	banner.Sanitize()
+	b.cache.SetUserBanner(featureID, tagID, isAdmin, bannerContent)
 
-	return banner, nil
+	return bannerContent, nil
 }
 
-func (b *BannerService) DeleteBanner(ctx context.Context, bannerID uint64, userID uint64) error {
-	err := b.storage.DeleteBanner(ctx, bannerID, userID)
+// DeleteBanner deletes banner on behalf of principal, which must have banner:delete on its feature.
+func (b *BannerService) DeleteBanner(ctx context.Context, bannerID uint64, principal *models.Principal) error {
+	if _, err := b.authorizeBanner(ctx, principal, models.PermBannerDelete, bannerID); err != nil {
+		return err
+	}
+
+	err := b.storage.DeleteBanner(ctx, bannerID)
 	if err != nil {
 		return fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
+	b.cache.Invalidate()
+
 	return nil
 }
 
-func (b *BannerService) UpdateBanner(ctx context.Context, r io.Reader, bannerID uint64, userID uint64) error {
-	preBanner, err := ValidatePreBanner(r)
+// UpdateBanner applies partial update from r to banner only if banner is still at expectedRevision
+// and returns its new revision. Principal must have banner:write on feature of banner and, if banner
+// is moved to another feature, on that feature too.
+func (b *BannerService) UpdateBanner(ctx context.Context, r io.Reader, bannerID uint64,
+	principal *models.Principal, expectedRevision uint64) (uint64, error) {
+	bannerPatch, err := ValidateBannerPatch(r)
 	if err != nil {
-		return fmt.Errorf(myerrors.ErrTemplate, err)
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	err = b.storage.UpdateBanner(ctx, preBanner, bannerID, userID)
+	banner, err := b.authorizeBanner(ctx, principal, models.PermBannerWrite, bannerID)
 	if err != nil {
-		return fmt.Errorf(myerrors.ErrTemplate, err)
+		return 0, err
 	}
 
-	return nil
+	// patch must be applied to the revision client has seen, storage checks it again on write
+	if banner.Revision != expectedRevision {
+		return 0, bannerrepo.NewRevisionConflictError(bannerID, banner.Revision)
+	}
+
+	preBanner := bannerPatch.Apply(banner)
+
+	if preBanner.FeatureID != banner.FeatureID {
+		if err := Authorize(principal, models.PermBannerWrite, preBanner.FeatureID); err != nil {
+			return 0, err
+		}
+	}
+
+	err = b.storage.CheckBannerReferences(ctx, preBanner.FeatureID, preBanner.TagIDs)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	// content that is kept under the same feature is not checked again, so toggling
+	// activity of banner does not fail if schema of feature has changed since
+	if bannerPatch.Content != nil || preBanner.FeatureID != banner.FeatureID {
+		schema, err := b.getContentSchema(ctx, preBanner.FeatureID)
+		if err != nil {
+			return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if schema != nil {
+			err = ValidateContentBySchema(preBanner.Content, schema)
+			if err != nil {
+				return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+			}
+		}
+	}
+
+	revision, err := b.storage.UpdateBanner(ctx, preBanner, bannerID, principal.UserID, expectedRevision)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	b.cache.Invalidate()
+
+	return revision, nil
+}
+
+// TransferBanner makes user with authorID author of banner if it is still at expectedRevision
+// and returns new revision of banner. Principal must have banner:write on feature of banner.
+func (b *BannerService) TransferBanner(ctx context.Context, bannerID uint64, authorID uint64,
+	principal *models.Principal, expectedRevision uint64) (uint64, error) {
+	if _, err := b.authorizeBanner(ctx, principal, models.PermBannerWrite, bannerID); err != nil {
+		return 0, err
+	}
+
+	revision, err := b.storage.SetBannerAuthor(ctx, bannerID, authorID, principal.UserID, expectedRevision)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	// content is the same, but cached revision of banner is outdated
+	b.cache.Invalidate()
+
+	return revision, nil
 }
 
+// GetBannersList returns banners on behalf of principal, which must have banner:read on feature,
+// or on all features if featureID is 0, because then banners of all features are listed.
 func (b *BannerService) GetBannersList(ctx context.Context, featureID uint64, tagID uint64, limit uint64,
-	offset uint64) ([]*models.Banner, error) {
+	offset uint64, principal *models.Principal) ([]*models.Banner, error) {
+	if err := Authorize(principal, models.PermBannerRead, featureID); err != nil {
+		return nil, err
+	}
+
 	banners, err := b.storage.GetBannersList(ctx, featureID, tagID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	for _, banner := range banners {
-		banner.Sanitize()
-	}
-
 	return banners, nil
 }
+
+func (b *BannerService) GetCacheStats() models.BannerCacheStats {
+	return b.cache.Stats()
+}
+
+// HandleBannersChanged is called when banners were changed by this or another instance of service.
+func (b *BannerService) HandleBannersChanged() {
+	b.cache.Invalidate()
+}
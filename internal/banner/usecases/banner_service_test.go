@@ -0,0 +1,513 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	bannerrepo "github.com/SanExpett/banners-backend/internal/banner/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errStorageDown = errors.New("storage is down")
+
+func TestMain(m *testing.M) {
+	if _, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// fakeBannerStorage implements only methods used by test, the others panic.
+type fakeBannerStorage struct {
+	IBannerStorage
+	banner json.RawMessage
+	// bannerRevision is returned by GetBanner with banner
+	bannerRevision uint64
+	userBanner     json.RawMessage
+	banners        []*models.Banner
+	// schema is the current schema of every feature, nil if features have no schema
+	schema        json.RawMessage
+	bannerVersion *models.BannerVersion
+	// current is the stored banner that is patched by update, storedBanner() if nil
+	current *models.Banner
+	// referencesErr is returned by CheckBannerReferences
+	referencesErr error
+	err           error
+
+	reads        int
+	restored     bool
+	updated      *models.PreBanner
+	added        *models.PreBanner
+	deleted      bool
+	gotAuthorID  uint64
+	gotTagIDs    []uint64
+	gotBannerID  uint64
+	gotFeatureID uint64
+	gotTagID     uint64
+	gotIsAdmin   bool
+	gotVersion   uint64
+	gotUserID    uint64
+	gotRevision  uint64
+}
+
+func (s *fakeBannerStorage) GetBanner(_ context.Context, bannerID uint64, isAdmin bool) (json.RawMessage, uint64,
+	error) {
+	s.reads++
+	s.gotBannerID, s.gotIsAdmin = bannerID, isAdmin
+
+	return s.banner, s.bannerRevision, s.err
+}
+
+func (s *fakeBannerStorage) GetUserBanner(_ context.Context, featureID uint64, tagID uint64,
+	isAdmin bool) (json.RawMessage, error) {
+	s.reads++
+	s.gotFeatureID, s.gotTagID, s.gotIsAdmin = featureID, tagID, isAdmin
+
+	return s.userBanner, s.err
+}
+
+func (s *fakeBannerStorage) GetBannersContentByFeature(_ context.Context, featureID uint64) ([]*models.Banner,
+	error) {
+	s.gotFeatureID = featureID
+
+	return s.banners, s.err
+}
+
+func (s *fakeBannerStorage) GetBannersList(_ context.Context, featureID uint64, tagID uint64, _ uint64,
+	_ uint64) ([]*models.Banner, error) {
+	s.reads++
+	s.gotFeatureID, s.gotTagID = featureID, tagID
+
+	return s.banners, s.err
+}
+
+func (s *fakeBannerStorage) GetFeatureSchema(_ context.Context, featureID uint64,
+	version uint64) (*models.FeatureSchema, error) {
+	if s.schema == nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, bannerrepo.ErrFeatureSchemaNotFound)
+	}
+
+	return &models.FeatureSchema{FeatureID: featureID, Version: version, Schema: s.schema}, nil //nolint:exhaustruct
+}
+
+func (s *fakeBannerStorage) GetBannerVersion(_ context.Context, bannerID uint64,
+	version uint64) (*models.BannerVersion, error) {
+	s.gotBannerID, s.gotVersion = bannerID, version
+
+	return s.bannerVersion, s.err
+}
+
+func (s *fakeBannerStorage) RestoreBannerVersion(_ context.Context, bannerID uint64, version uint64,
+	userID uint64, expectedRevision uint64) (uint64, error) {
+	s.restored = true
+	s.gotBannerID, s.gotVersion, s.gotUserID, s.gotRevision = bannerID, version, userID, expectedRevision
+
+	return expectedRevision + 1, s.err
+}
+
+func (s *fakeBannerStorage) UpdateBanner(_ context.Context, preBanner *models.PreBanner, bannerID uint64,
+	userID uint64, expectedRevision uint64) (uint64, error) {
+	s.updated = preBanner
+	s.gotBannerID, s.gotUserID, s.gotRevision = bannerID, userID, expectedRevision
+
+	return expectedRevision + 1, s.err
+}
+
+func (s *fakeBannerStorage) AddBannerDeleteJob(_ context.Context, featureID uint64, tagID uint64,
+	userID uint64) (uint64, error) {
+	s.gotFeatureID, s.gotTagID, s.gotUserID = featureID, tagID, userID
+
+	return 1, s.err
+}
+
+func storedBanner() *models.Banner {
+	return &models.Banner{ //nolint:exhaustruct
+		BannerID:  1,
+		TagIDs:    []uint64{1},
+		FeatureID: 3,
+		AuthorID:  1,
+		Content:   json.RawMessage(`{"title": "old"}`),
+		IsActive:  true,
+		Revision:  4,
+	}
+}
+
+func (s *fakeBannerStorage) GetBannerByID(_ context.Context, bannerID uint64) (*models.Banner, error) {
+	s.gotBannerID = bannerID
+
+	if s.current == nil {
+		return storedBanner(), nil
+	}
+
+	return s.current, nil
+}
+
+func (s *fakeBannerStorage) DeleteBanner(_ context.Context, bannerID uint64) error {
+	s.deleted = true
+	s.gotBannerID = bannerID
+
+	return s.err
+}
+
+func (s *fakeBannerStorage) SetBannerAuthor(_ context.Context, bannerID uint64, authorID uint64, userID uint64,
+	expectedRevision uint64) (uint64, error) {
+	s.gotBannerID, s.gotAuthorID, s.gotUserID, s.gotRevision = bannerID, authorID, userID, expectedRevision
+
+	return 5, s.err
+}
+
+func (s *fakeBannerStorage) CheckBannerReferences(_ context.Context, featureID uint64, tagIDs []uint64) error {
+	s.gotFeatureID, s.gotTagIDs = featureID, tagIDs
+
+	return s.referencesErr
+}
+
+func (s *fakeBannerStorage) AddBanner(_ context.Context, preBanner *models.PreBanner, userID uint64) (uint64,
+	error) {
+	s.added = preBanner
+	s.gotUserID = userID
+
+	return 1, s.err
+}
+
+// testPrincipal returns user with userID, roles are added to RoleUser.
+func testPrincipal(userID uint64, roles ...string) *models.Principal {
+	return &models.Principal{ //nolint:exhaustruct
+		UserID: userID,
+		Login:  "user",
+		Roles:  append([]string{models.RoleUser}, roles...),
+	}
+}
+
+func testAdmin() *models.Principal {
+	return testPrincipal(7, models.RoleAdmin)
+}
+
+func isErrKind(err error, kind myerrors.Kind) bool {
+	myErr := &myerrors.Error{}
+
+	return errors.As(err, &myErr) && myErr.Kind() == kind
+}
+
+type fakeBannerDeleteWorker struct {
+	wakes int
+}
+
+func (w *fakeBannerDeleteWorker) Wake() {
+	w.wakes++
+}
+
+func newTestBannerService(t *testing.T, storage *fakeBannerStorage) *BannerService {
+	t.Helper()
+
+	bannerService, err := NewBannerService(storage, NewBannerCache(time.Hour), &fakeBannerDeleteWorker{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bannerService
+}
+
+func TestBannerServiceGetUserBanner(t *testing.T) {
+	t.Parallel()
+
+	storage := &fakeBannerStorage{ //nolint:exhaustruct
+		userBanner: json.RawMessage(`{"title": "<b>title</b>", "items": [1, 2]}`),
+	}
+	bannerService := newTestBannerService(t, storage)
+
+	banner, err := bannerService.GetUserBanner(context.Background(), 3, 7, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if storage.gotFeatureID != 3 || storage.gotTagID != 7 || !storage.gotIsAdmin {
+		t.Errorf("storage is asked for feature %d, tag %d, admin %t, want 3, 7, true",
+			storage.gotFeatureID, storage.gotTagID, storage.gotIsAdmin)
+	}
+
+	// content is arbitrary json, it is returned verbatim
+	if string(banner) != `{"title": "<b>title</b>", "items": [1, 2]}` {
+		t.Errorf("GetUserBanner() = %s, want content from storage", banner)
+	}
+}
+
+func TestBannerServiceGetUserBannerError(t *testing.T) {
+	t.Parallel()
+
+	bannerService := newTestBannerService(t, &fakeBannerStorage{err: errStorageDown}) //nolint:exhaustruct
+
+	if _, err := bannerService.GetUserBanner(context.Background(), 3, 7, false, false); !errors.Is(err, errStorageDown) {
+		t.Errorf("err = %v, want %v", err, errStorageDown)
+	}
+}
+
+func TestBannerServiceHandleBannersChanged(t *testing.T) {
+	t.Parallel()
+
+	storage := &fakeBannerStorage{banner: json.RawMessage(`{"revision": 1}`)} //nolint:exhaustruct
+	bannerService := newTestBannerService(t, storage)
+	ctx := context.Background()
+
+	if _, _, err := bannerService.GetBanner(ctx, 1, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// banner is changed by another instance, which notifies this one
+	storage.banner = json.RawMessage(`{"revision": 2}`)
+	bannerService.HandleBannersChanged()
+
+	content, _, err := bannerService.GetBanner(ctx, 1, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != `{"revision": 2}` || storage.reads != 2 {
+		t.Errorf("GetBanner() = %s with %d reads of storage, want revision 2 read from storage",
+			content, storage.reads)
+	}
+}
+
+func TestBannerServiceUpdateBanner(t *testing.T) {
+	t.Parallel()
+
+	storage := &fakeBannerStorage{banner: json.RawMessage(`{"title": "old"}`)} //nolint:exhaustruct
+	bannerService := newTestBannerService(t, storage)
+	ctx := context.Background()
+
+	if _, _, err := bannerService.GetBanner(ctx, 1, true, false); err != nil {
+		t.Fatal(err)
+	}
+
+	revision, err := bannerService.UpdateBanner(ctx, strings.NewReader(
+		`{"tag_ids": [1], "feature_id": 3, "content": {"title": "new"}, "is_active": true}`), 1, testAdmin(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revision != 5 || storage.gotBannerID != 1 || storage.gotUserID != 7 || storage.gotRevision != 4 {
+		t.Errorf("UpdateBanner() = %d, storage updates banner %d by user %d at revision %d, want 5, 1, 7, 4",
+			revision, storage.gotBannerID, storage.gotUserID, storage.gotRevision)
+	}
+
+	if storage.updated == nil || string(storage.updated.Content) != `{"title": "new"}` {
+		t.Errorf("storage updates banner to %+v", storage.updated)
+	}
+
+	storage.reads = 0
+	if _, _, err := bannerService.GetBanner(ctx, 1, true, false); err != nil || storage.reads != 1 {
+		t.Errorf("banner is not read from storage after update: %d reads, err %v", storage.reads, err)
+	}
+}
+
+func TestBannerServiceUpdateBannerStale(t *testing.T) {
+	t.Parallel()
+
+	conflictErr := myerrors.NewConflictError(models.RevisionConflict{BannerID: 1, CurrentRevision: 5},
+		"stale", myerrors.Message{RU: "устарел", EN: "stale"})
+	bannerService := newTestBannerService(t, &fakeBannerStorage{err: conflictErr}) //nolint:exhaustruct
+
+	_, err := bannerService.UpdateBanner(context.Background(), strings.NewReader(
+		`{"tag_ids": [1], "feature_id": 3, "content": {"title": "new"}, "is_active": true}`), 1, testAdmin(), 4)
+	if !errors.Is(err, conflictErr) {
+		t.Errorf("err = %v, want %v", err, conflictErr)
+	}
+}
+
+func TestBannerServiceMissingReferences(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"tag_ids": [1, 2], "feature_id": 3, "content": {"title": "t"}, "is_active": true}`
+
+	referencesErr := myerrors.NewValidationError(models.MissingReferences{TagIDs: []uint64{2}}, //nolint:exhaustruct
+		"missing", myerrors.Message{RU: "нет тега 2", EN: "no tag 2"})
+	ctx := context.Background()
+
+	t.Run("add", func(t *testing.T) {
+		t.Parallel()
+
+		storage := &fakeBannerStorage{referencesErr: referencesErr} //nolint:exhaustruct
+		bannerService := newTestBannerService(t, storage)
+
+		if _, err := bannerService.AddBanner(ctx, strings.NewReader(body), testAdmin()); !errors.Is(err, referencesErr) {
+			t.Fatalf("err = %v, want %v", err, referencesErr)
+		}
+
+		if storage.gotFeatureID != 3 || !slices.Equal(storage.gotTagIDs, []uint64{1, 2}) {
+			t.Errorf("references are checked for feature %d, tags %v", storage.gotFeatureID, storage.gotTagIDs)
+		}
+
+		if storage.added != nil {
+			t.Error("banner with missing references is added")
+		}
+	})
+
+	t.Run("update checks patched banner", func(t *testing.T) {
+		t.Parallel()
+
+		storage := &fakeBannerStorage{referencesErr: referencesErr} //nolint:exhaustruct
+		bannerService := newTestBannerService(t, storage)
+
+		_, err := bannerService.UpdateBanner(ctx, strings.NewReader(body), 1, testAdmin(), 4)
+		if !errors.Is(err, referencesErr) {
+			t.Fatalf("err = %v, want %v", err, referencesErr)
+		}
+
+		if storage.gotFeatureID != 3 || !slices.Equal(storage.gotTagIDs, []uint64{1, 2}) {
+			t.Errorf("references are checked for feature %d, tags %v", storage.gotFeatureID, storage.gotTagIDs)
+		}
+
+		if storage.updated != nil {
+			t.Error("banner with missing references is updated")
+		}
+	})
+}
+
+func TestBannerServiceUpdateBannerPatch(t *testing.T) {
+	t.Parallel()
+
+	// stored banner {"title": "old"} does not match this schema
+	const sizeSchema = `{"type": "object", "required": ["size"]}`
+
+	tests := []struct {
+		name        string
+		body        string
+		wantErr     bool
+		wantContent string
+		wantActive  bool
+		wantTagIDs  []uint64
+	}{
+		{
+			name:        "toggling activity keeps content unchecked",
+			body:        `{"is_active": false}`,
+			wantContent: `{"title": "old"}`,
+			wantActive:  false,
+			wantTagIDs:  []uint64{1},
+		},
+		{
+			name:        "tags replace tag set",
+			body:        `{"tag_ids": [5, 6]}`,
+			wantContent: `{"title": "old"}`,
+			wantActive:  true,
+			wantTagIDs:  []uint64{5, 6},
+		},
+		{
+			name:        "new content is checked by schema",
+			body:        `{"content": {"size": 2}}`,
+			wantContent: `{"size": 2}`,
+			wantActive:  true,
+			wantTagIDs:  []uint64{1},
+		},
+		{
+			name:    "new content violates schema",
+			body:    `{"content": {"title": "new"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "kept content violates schema of new feature",
+			body:    `{"feature_id": 4}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &fakeBannerStorage{schema: json.RawMessage(sizeSchema)} //nolint:exhaustruct
+			bannerService := newTestBannerService(t, storage)
+
+			_, err := bannerService.UpdateBanner(context.Background(), strings.NewReader(tt.body), 1, testAdmin(), 4)
+			if tt.wantErr {
+				if !isErrKind(err, myerrors.KindValidation) || storage.updated != nil {
+					t.Errorf("err = %v, updated = %+v, want validation error and no update", err, storage.updated)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if string(storage.updated.Content) != tt.wantContent || storage.updated.IsActive != tt.wantActive ||
+				!slices.Equal(storage.updated.TagIDs, tt.wantTagIDs) {
+				t.Errorf("storage updates banner to %+v", storage.updated)
+			}
+		})
+	}
+}
+
+func TestBannerServiceUpdateBannerOutdatedPatch(t *testing.T) {
+	t.Parallel()
+
+	current := storedBanner()
+	current.Revision = 5
+	storage := &fakeBannerStorage{current: current} //nolint:exhaustruct
+	bannerService := newTestBannerService(t, storage)
+
+	_, err := bannerService.UpdateBanner(context.Background(), strings.NewReader(`{"is_active": false}`), 1,
+		testAdmin(), 4)
+
+	if !isErrKind(err, myerrors.KindConflict) {
+		t.Fatalf("err = %v, want conflict error", err)
+	}
+
+	if storage.updated != nil {
+		t.Error("patch of outdated revision is written")
+	}
+}
+
+func TestBannerServiceTransferBanner(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := &fakeBannerStorage{banner: json.RawMessage(`{}`), bannerRevision: 4} //nolint:exhaustruct
+	bannerService := newTestBannerService(t, storage)
+
+	if _, _, err := bannerService.GetBanner(ctx, 1, true, false); err != nil {
+		t.Fatal(err)
+	}
+
+	revision, err := bannerService.TransferBanner(ctx, 1, 2, testAdmin(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// banner with revision 4 cached before transfer is read from storage again
+	storage.reads, storage.bannerRevision = 0, 5
+	if _, cachedRevision, err := bannerService.GetBanner(ctx, 1, true, false); err != nil || storage.reads != 1 ||
+		cachedRevision != 5 {
+		t.Errorf("GetBanner() after transfer = revision %d with %d reads of storage, err %v, want revision 5",
+			cachedRevision, storage.reads, err)
+	}
+
+	if revision != 5 || storage.gotBannerID != 1 || storage.gotAuthorID != 2 {
+		t.Errorf("TransferBanner() = %d, storage makes user %d author of banner %d, want 5, 2, 1",
+			revision, storage.gotAuthorID, storage.gotBannerID)
+	}
+
+	// transfer is saved as version of banner changed by admin, only if banner is not changed since revision 4
+	if storage.gotUserID != 7 || storage.gotRevision != 4 {
+		t.Errorf("storage got user %d and revision %d, want 7 and 4", storage.gotUserID, storage.gotRevision)
+	}
+
+	notFoundErr := myerrors.NewNotFoundError("user_not_found", myerrors.Message{RU: "нет", EN: "no"})
+	storage.err = notFoundErr
+
+	if _, err := bannerService.TransferBanner(ctx, 1, 100, testAdmin(), 4); !errors.Is(err,
+		notFoundErr) {
+		t.Errorf("err = %v, want %v", err, notFoundErr)
+	}
+}
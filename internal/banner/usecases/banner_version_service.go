@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+)
+
+func (b *BannerService) GetBannerVersions(ctx context.Context, bannerID uint64,
+	principal *models.Principal) ([]*models.BannerVersion, error) {
+	if _, err := b.authorizeBanner(ctx, principal, models.PermBannerRead, bannerID); err != nil {
+		return nil, err
+	}
+
+	bannerVersions, err := b.storage.GetBannerVersions(ctx, bannerID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannerVersions, nil
+}
+
+func (b *BannerService) GetBannerVersion(ctx context.Context, bannerID uint64,
+	version uint64, principal *models.Principal) (*models.BannerVersion, error) {
+	if _, err := b.authorizeBanner(ctx, principal, models.PermBannerRead, bannerID); err != nil {
+		return nil, err
+	}
+
+	bannerVersion, err := b.storage.GetBannerVersion(ctx, bannerID, version)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannerVersion, nil
+}
+
+// RestoreBannerVersion makes given version of banner current. Content of version
+// must satisfy the current schema of its feature. Banner is restored only if it is still
+// at expectedRevision. Principal must have banner:write on the current feature of banner
+// and on feature of version.
+func (b *BannerService) RestoreBannerVersion(ctx context.Context, bannerID uint64, version uint64,
+	principal *models.Principal, expectedRevision uint64) (uint64, error) {
+	if _, err := b.authorizeBanner(ctx, principal, models.PermBannerWrite, bannerID); err != nil {
+		return 0, err
+	}
+
+	bannerVersion, err := b.storage.GetBannerVersion(ctx, bannerID, version)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if err := Authorize(principal, models.PermBannerWrite, bannerVersion.FeatureID); err != nil {
+		return 0, err
+	}
+
+	// feature or tags of version could be deleted since it was saved
+	err = b.storage.CheckBannerReferences(ctx, bannerVersion.FeatureID, bannerVersion.TagIDs)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	schema, err := b.getContentSchema(ctx, bannerVersion.FeatureID)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if schema != nil {
+		err = ValidateContentBySchema(bannerVersion.Content, schema)
+		if err != nil {
+			return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
+	revision, err := b.storage.RestoreBannerVersion(ctx, bannerID, version, principal.UserID, expectedRevision)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	b.cache.Invalidate()
+
+	return revision, nil
+}
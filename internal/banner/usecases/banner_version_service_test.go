@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"testing"
+)
+
+func TestBannerServiceRestoreBannerVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		schema       string
+		content      string
+		wantRestored bool
+	}{
+		{name: "feature without schema", schema: "", content: `{"size": 1}`, wantRestored: true},
+		{name: "content matches current schema", schema: testContentSchema, content: `{"title": "t"}`, wantRestored: true},
+		{name: "content does not match current schema", schema: testContentSchema, content: `{"size": 1}`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &fakeBannerStorage{ //nolint:exhaustruct
+				banner: json.RawMessage(`{"title": "current"}`),
+				bannerVersion: &models.BannerVersion{ //nolint:exhaustruct
+					BannerID: 1, Version: 2, FeatureID: 3, Content: json.RawMessage(tt.content),
+				},
+			}
+			if tt.schema != "" {
+				storage.schema = json.RawMessage(tt.schema)
+			}
+
+			bannerService := newTestBannerService(t, storage)
+			ctx := context.Background()
+
+			// fill cache, restored banner must not be read from it
+			if _, _, err := bannerService.GetBanner(ctx, 1, true, false); err != nil {
+				t.Fatal(err)
+			}
+
+			revision, err := bannerService.RestoreBannerVersion(ctx, 1, 2, testAdmin(), 4)
+			if storage.restored != tt.wantRestored {
+				t.Fatalf("restored = %t, want %t, err = %v", storage.restored, tt.wantRestored, err)
+			}
+
+			if !tt.wantRestored {
+				if !isErrKind(err, myerrors.KindValidation) {
+					t.Errorf("err = %v, want validation error", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if revision != 5 || storage.gotBannerID != 1 || storage.gotVersion != 2 || storage.gotUserID != 7 ||
+				storage.gotRevision != 4 {
+				t.Errorf("RestoreBannerVersion() = %d, storage restores banner %d version %d by user %d "+
+					"at revision %d, want 5, 1, 2, 7, 4", revision, storage.gotBannerID, storage.gotVersion,
+					storage.gotUserID, storage.gotRevision)
+			}
+
+			storage.reads = 0
+			if _, _, err := bannerService.GetBanner(ctx, 1, true, false); err != nil || storage.reads != 1 {
+				t.Errorf("banner is not read from storage after restore: %d reads, err %v", storage.reads, err)
+			}
+		})
+	}
+}
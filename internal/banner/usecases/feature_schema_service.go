@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	bannerrepo "github.com/SanExpett/banners-backend/internal/banner/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"io"
+)
+
+func (b *BannerService) AddFeatureSchema(ctx context.Context, featureID uint64, r io.Reader,
+	userID uint64) (uint64, error) {
+	rawSchema, _, err := ValidateFeatureSchema(r)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	version, err := b.storage.AddFeatureSchema(ctx, featureID, rawSchema, userID)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return version, nil
+}
+
+func (b *BannerService) GetFeatureSchema(ctx context.Context, featureID uint64,
+	version uint64) (*models.FeatureSchema, error) {
+	featureSchema, err := b.storage.GetFeatureSchema(ctx, featureID, version)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return featureSchema, nil
+}
+
+func (b *BannerService) GetFeatureSchemasList(ctx context.Context,
+	featureID uint64) ([]*models.FeatureSchema, error) {
+	featureSchemas, err := b.storage.GetFeatureSchemasList(ctx, featureID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return featureSchemas, nil
+}
+
+// CheckFeatureSchema reports banners of feature whose content would not satisfy the proposed schema.
+// The schema is not saved.
+func (b *BannerService) CheckFeatureSchema(ctx context.Context, featureID uint64,
+	r io.Reader) ([]models.BannerSchemaViolation, error) {
+	_, schema, err := ValidateFeatureSchema(r)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	banners, err := b.storage.GetBannersContentByFeature(ctx, featureID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	violations := make([]models.BannerSchemaViolation, 0)
+
+	for _, banner := range banners {
+		contentErrors, err := collectContentErrors(banner.Content, schema)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if len(contentErrors) != 0 {
+			violations = append(violations, models.BannerSchemaViolation{
+				BannerID: banner.BannerID,
+				Errors:   contentErrors,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// getContentSchema returns compiled current schema of feature or nil if feature has no schema.
+func (b *BannerService) getContentSchema(ctx context.Context, featureID uint64) (*jsonschema.Schema, error) {
+	featureSchema, err := b.storage.GetFeatureSchema(ctx, featureID, 0)
+	if err != nil {
+		if errors.Is(err, bannerrepo.ErrFeatureSchemaNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	schema, err := CompileContentSchema(featureSchema.Schema)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return schema, nil
+}
+
+func (b *BannerService) contentSchemaGetter(ctx context.Context) func(featureID uint64) (*jsonschema.Schema, error) {
+	return func(featureID uint64) (*jsonschema.Schema, error) {
+		return b.getContentSchema(ctx, featureID)
+	}
+}
@@ -0,0 +1,50 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"strings"
+	"testing"
+)
+
+func TestBannerServiceCheckFeatureSchema(t *testing.T) {
+	t.Parallel()
+
+	storage := &fakeBannerStorage{banners: []*models.Banner{ //nolint:exhaustruct
+		{BannerID: 1, Content: json.RawMessage(`{"title": "ok"}`)},   //nolint:exhaustruct
+		{BannerID: 2, Content: json.RawMessage(`{"size": 1}`)},       //nolint:exhaustruct
+		{BannerID: 3, Content: json.RawMessage(`{"title": 1}`)},      //nolint:exhaustruct
+		{BannerID: 4, Content: json.RawMessage(`{"title": "also"}`)}, //nolint:exhaustruct
+	}}
+	bannerService := newTestBannerService(t, storage)
+
+	violations, err := bannerService.CheckFeatureSchema(context.Background(), 5, strings.NewReader(testContentSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if storage.gotFeatureID != 5 {
+		t.Errorf("banners are asked for feature %d, want 5", storage.gotFeatureID)
+	}
+
+	if len(violations) != 2 || violations[0].BannerID != 2 || violations[1].BannerID != 3 {
+		t.Fatalf("violations = %+v, want banners 2 and 3", violations)
+	}
+
+	if len(violations[1].Errors) != 1 || violations[1].Errors[0].Path != "/title" {
+		t.Errorf("errors of banner 3 = %+v, want one error at /title", violations[1].Errors)
+	}
+}
+
+func TestBannerServiceCheckFeatureSchemaInvalid(t *testing.T) {
+	t.Parallel()
+
+	bannerService := newTestBannerService(t, &fakeBannerStorage{}) //nolint:exhaustruct
+
+	_, err := bannerService.CheckFeatureSchema(context.Background(), 5, strings.NewReader(`{"type": 5}`))
+	if !errors.Is(err, ErrInvalidFeatureSchema) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidFeatureSchema)
+	}
+}
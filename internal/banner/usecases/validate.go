@@ -7,14 +7,38 @@ import (
 	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
 	"github.com/SanExpett/banners-backend/pkg/my_logger"
 	"github.com/asaskevich/govalidator"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"io"
 )
 
 var (
-	ErrDecodePreBanner = myerrors.NewError("Некорректный json баннера")
+	ErrDecodePreBanner = myerrors.NewError("bad_banner_json", myerrors.Message{
+		RU: "Некорректный json баннера",
+		EN: "Invalid json of banner",
+	})
+	ErrDuplicateTagIDs = myerrors.NewError("duplicate_tag_ids", myerrors.Message{
+		RU: "Теги баннера не должны повторяться",
+		EN: "Tags of banner must not repeat",
+	})
+	ErrContentNotObject = myerrors.NewError("content_not_object", myerrors.Message{
+		RU: "Контент баннера должен быть json объектом",
+		EN: "Content of banner must be json object",
+	})
+	ErrEmptyTagIDs = myerrors.NewError("empty_tag_ids", myerrors.Message{
+		RU: "У баннера должен быть хотя бы один тег",
+		EN: "Banner must have at least one tag",
+	})
+	ErrEmptyFeatureID = myerrors.NewError("empty_feature_id", myerrors.Message{
+		RU: "Фича баннера должна быть указана",
+		EN: "Feature of banner must be given",
+	})
 )
 
-func ValidatePreBanner(r io.Reader) (*models.PreBanner, error) {
+// ValidatePreBanner decodes banner from r and checks it. If getSchema returns
+// not nil schema for banner feature, content of banner is validated against it.
+func ValidatePreBanner(r io.Reader,
+	getSchema func(featureID uint64) (*jsonschema.Schema, error),
+) (*models.PreBanner, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, err
@@ -28,8 +52,6 @@ func ValidatePreBanner(r io.Reader) (*models.PreBanner, error) {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreBanner)
 	}
 
-	preBanner.Trim()
-
 	_, err = govalidator.ValidateStruct(preBanner)
 	if err != nil {
 		logger.Errorln(err)
@@ -37,5 +59,82 @@ func ValidatePreBanner(r io.Reader) (*models.PreBanner, error) {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
+	if hasDuplicates(preBanner.TagIDs) {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDuplicateTagIDs)
+	}
+
+	if !isJSONObject(preBanner.Content) {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrContentNotObject)
+	}
+
+	schema, err := getSchema(preBanner.FeatureID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if schema != nil {
+		err = ValidateContentBySchema(preBanner.Content, schema)
+		if err != nil {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
 	return preBanner, nil
 }
+
+// ValidateBannerPatch decodes partial update of banner from r and checks the fields that are set.
+// Content is checked against schema of feature later, when the whole banner is known.
+func ValidateBannerPatch(r io.Reader) (*models.BannerPatch, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(r)
+	bannerPatch := &models.BannerPatch{} //nolint:exhaustruct
+	if err := decoder.Decode(bannerPatch); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreBanner)
+	}
+
+	if bannerPatch.TagIDs != nil {
+		if len(*bannerPatch.TagIDs) == 0 {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrEmptyTagIDs)
+		}
+
+		if hasDuplicates(*bannerPatch.TagIDs) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDuplicateTagIDs)
+		}
+	}
+
+	if bannerPatch.FeatureID != nil && *bannerPatch.FeatureID == 0 {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrEmptyFeatureID)
+	}
+
+	if bannerPatch.Content != nil && !isJSONObject(bannerPatch.Content) {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrContentNotObject)
+	}
+
+	return bannerPatch, nil
+}
+
+func isJSONObject(content json.RawMessage) bool {
+	var object map[string]json.RawMessage
+
+	return json.Unmarshal(content, &object) == nil && object != nil
+}
+
+func hasDuplicates(ids []uint64) bool {
+	seen := make(map[uint64]struct{}, len(ids))
+
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			return true
+		}
+
+		seen[id] = struct{}{}
+	}
+
+	return false
+}
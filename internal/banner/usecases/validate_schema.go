@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"io"
+)
+
+const (
+	contentSchemaURL = "content_schema.json"
+)
+
+var (
+	ErrDecodeFeatureSchema = myerrors.NewError("bad_feature_schema_json", myerrors.Message{
+		RU: "Некорректный json схемы фичи",
+		EN: "Invalid json of feature schema",
+	})
+	ErrInvalidFeatureSchema = myerrors.NewError("invalid_feature_schema", myerrors.Message{
+		RU: "Некорректная json schema фичи",
+		EN: "Invalid json schema of feature",
+	})
+	ErrSchemaRefNotAllowed = myerrors.NewError("schema_ref_not_allowed", myerrors.Message{
+		RU: "Внешние $ref в схеме фичи не поддерживаются",
+		EN: "External $ref in feature schema are not supported",
+	})
+
+	MessageErrContentNotMatchSchema = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Контент баннера не соответствует схеме фичи",
+		EN: "Content of banner does not match schema of feature",
+	}
+)
+
+// ValidateFeatureSchema decodes JSON Schema from r and checks that it compiles.
+func ValidateFeatureSchema(r io.Reader) (json.RawMessage, *jsonschema.Schema, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	var rawSchema json.RawMessage
+
+	decoder := json.NewDecoder(r)
+	if err := decoder.Decode(&rawSchema); err != nil {
+		logger.Errorln(err)
+
+		return nil, nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodeFeatureSchema)
+	}
+
+	schema, err := CompileContentSchema(rawSchema)
+	if err != nil {
+		return nil, nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return rawSchema, schema, nil
+}
+
+func CompileContentSchema(rawSchema json.RawMessage) (*jsonschema.Schema, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(string) (io.ReadCloser, error) {
+		return nil, ErrSchemaRefNotAllowed
+	}
+
+	if err := compiler.AddResource(contentSchemaURL, bytes.NewReader(rawSchema)); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidFeatureSchema)
+	}
+
+	schema, err := compiler.Compile(contentSchemaURL)
+	if err != nil {
+		logger.Errorln(err)
+
+		if errors.Is(err, ErrSchemaRefNotAllowed) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrSchemaRefNotAllowed)
+		}
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidFeatureSchema)
+	}
+
+	return schema, nil
+}
+
+// ValidateContentBySchema returns myerrors.ValidationError with every
+// mismatch of content and schema in details.
+func ValidateContentBySchema(content json.RawMessage, schema *jsonschema.Schema) error {
+	contentErrors, err := collectContentErrors(content, schema)
+	if err != nil {
+		return err
+	}
+
+	if len(contentErrors) != 0 {
+		return myerrors.NewValidationError(contentErrors, "content_not_match_schema", MessageErrContentNotMatchSchema)
+	}
+
+	return nil
+}
+
+func collectContentErrors(content json.RawMessage, schema *jsonschema.Schema) ([]models.ContentValidationError,
+	error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrContentNotObject)
+	}
+
+	err := schema.Validate(value)
+	if err == nil {
+		return nil, nil
+	}
+
+	validationErr := &jsonschema.ValidationError{}
+	if !errors.As(err, &validationErr) {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return appendLeafErrors(nil, validationErr), nil
+}
+
+func appendLeafErrors(contentErrors []models.ContentValidationError,
+	validationErr *jsonschema.ValidationError,
+) []models.ContentValidationError {
+	if len(validationErr.Causes) == 0 {
+		return append(contentErrors, models.ContentValidationError{
+			Path:    validationErr.InstanceLocation,
+			Message: validationErr.Message,
+		})
+	}
+
+	for _, cause := range validationErr.Causes {
+		contentErrors = appendLeafErrors(contentErrors, cause)
+	}
+
+	return contentErrors
+}
@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testContentSchema = `{
+	"type": "object",
+	"required": ["title"],
+	"properties": {
+		"title": {"type": "string"},
+		"size": {"type": "integer", "minimum": 1}
+	}
+}`
+
+func compileTestSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+
+	schema, err := CompileContentSchema(json.RawMessage(testContentSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return schema
+}
+
+func TestValidateFeatureSchema(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "valid schema", body: testContentSchema},
+		{name: "empty schema allows anything", body: `{}`},
+		{name: "local ref", body: `{"$defs": {"s": {"type": "string"}}, "properties": {"a": {"$ref": "#/$defs/s"}}}`},
+		{name: "bad json", body: `{"type": `, wantErr: ErrDecodeFeatureSchema},
+		{name: "bad keyword value", body: `{"type": 5}`, wantErr: ErrInvalidFeatureSchema},
+		{name: "external ref", body: `{"$ref": "http://example.com/schema.json"}`, wantErr: ErrSchemaRefNotAllowed},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rawSchema, schema, err := ValidateFeatureSchema(strings.NewReader(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (rawSchema == nil || schema == nil) {
+				t.Error("schema is nil without error")
+			}
+		})
+	}
+}
+
+func TestValidateContentBySchema(t *testing.T) {
+	t.Parallel()
+
+	schema := compileTestSchema(t)
+
+	tests := []struct {
+		name       string
+		content    string
+		wantErrors []models.ContentValidationError
+	}{
+		{name: "matching content", content: `{"title": "t", "size": 2, "extra": true}`},
+		{
+			name:    "every mismatch is reported",
+			content: `{"size": 0.5}`,
+			wantErrors: []models.ContentValidationError{
+				{Path: "", Message: "missing properties: 'title'"},
+				{Path: "/size", Message: "expected integer, but got number"},
+			},
+		},
+		{
+			name:       "wrong type of property",
+			content:    `{"title": 1}`,
+			wantErrors: []models.ContentValidationError{{Path: "/title", Message: "expected string, but got number"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateContentBySchema(json.RawMessage(tt.content), schema)
+			if tt.wantErrors == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+
+				return
+			}
+
+			validationErr := &myerrors.Error{}
+			if !errors.As(err, &validationErr) || validationErr.Kind() != myerrors.KindValidation {
+				t.Fatalf("err = %v, want validation error", err)
+			}
+
+			contentErrors, ok := validationErr.Details.([]models.ContentValidationError)
+			if !ok {
+				t.Fatalf("details = %T, want content errors", validationErr.Details)
+			}
+
+			slices.SortFunc(contentErrors, func(a, b models.ContentValidationError) int {
+				return strings.Compare(a.Path, b.Path)
+			})
+
+			if !slices.Equal(contentErrors, tt.wantErrors) {
+				t.Errorf("details = %+v, want %+v", contentErrors, tt.wantErrors)
+			}
+		})
+	}
+}
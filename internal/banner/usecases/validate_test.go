@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"errors"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func noSchema(uint64) (*jsonschema.Schema, error) {
+	return nil, nil
+}
+
+func TestValidatePreBanner(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{
+			name: "valid banner",
+			body: `{"tag_ids": [1, 2], "feature_id": 3, "content": {"title": "t", "items": [1, 2]}, "is_active": true}`,
+		},
+		{
+			name:    "bad json",
+			body:    `{"tag_ids": [1,`,
+			wantErr: ErrDecodePreBanner,
+		},
+		{
+			name:    "duplicate tags",
+			body:    `{"tag_ids": [1, 2, 1], "feature_id": 3, "content": {"title": "t"}, "is_active": true}`,
+			wantErr: ErrDuplicateTagIDs,
+		},
+		{
+			name:    "content is array",
+			body:    `{"tag_ids": [1], "feature_id": 3, "content": [1, 2], "is_active": true}`,
+			wantErr: ErrContentNotObject,
+		},
+		{
+			name:    "content is string",
+			body:    `{"tag_ids": [1], "feature_id": 3, "content": "text", "is_active": true}`,
+			wantErr: ErrContentNotObject,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			preBanner, err := ValidatePreBanner(strings.NewReader(tt.body), noSchema)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && preBanner == nil {
+				t.Error("banner is nil without error")
+			}
+		})
+	}
+}
+
+func TestValidatePreBannerBySchema(t *testing.T) {
+	t.Parallel()
+
+	schema := compileTestSchema(t)
+
+	var gotFeatureID uint64
+
+	getSchema := func(featureID uint64) (*jsonschema.Schema, error) {
+		gotFeatureID = featureID
+
+		return schema, nil
+	}
+
+	_, err := ValidatePreBanner(strings.NewReader(
+		`{"tag_ids": [1], "feature_id": 3, "content": {"title": "t"}, "is_active": true}`), getSchema)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if gotFeatureID != 3 {
+		t.Errorf("schema is asked for feature %d, want 3", gotFeatureID)
+	}
+
+	_, err = ValidatePreBanner(strings.NewReader(
+		`{"tag_ids": [1], "feature_id": 3, "content": {"size": 2}, "is_active": true}`), getSchema)
+
+	if !isErrKind(err, myerrors.KindValidation) {
+		t.Errorf("err = %v, want validation error", err)
+	}
+
+	_, err = ValidatePreBanner(strings.NewReader(
+		`{"tag_ids": [1], "feature_id": 3, "content": {"title": "t"}, "is_active": true}`),
+		func(uint64) (*jsonschema.Schema, error) {
+			return nil, errStorageDown
+		})
+	if !errors.Is(err, errStorageDown) {
+		t.Errorf("err = %v, want %v", err, errStorageDown)
+	}
+}
+
+func TestValidateBannerPatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "empty patch", body: `{}`},
+		{name: "all fields", body: `{"tag_ids": [1, 2], "feature_id": 3, "content": {"title": "a"}, "is_active": false}`},
+		{name: "only is_active", body: `{"is_active": true}`},
+		{name: "bad json", body: `{"tag_ids": [1,`, wantErr: ErrDecodePreBanner},
+		{name: "wrong type", body: `{"feature_id": "3"}`, wantErr: ErrDecodePreBanner},
+		{name: "empty tags", body: `{"tag_ids": []}`, wantErr: ErrEmptyTagIDs},
+		{name: "duplicate tags", body: `{"tag_ids": [1, 2, 1]}`, wantErr: ErrDuplicateTagIDs},
+		{name: "zero feature", body: `{"feature_id": 0}`, wantErr: ErrEmptyFeatureID},
+		{name: "content is array", body: `{"content": [1, 2]}`, wantErr: ErrContentNotObject},
+		{name: "content is string", body: `{"content": "text"}`, wantErr: ErrContentNotObject},
+		{name: "content is null", body: `{"content": null}`, wantErr: ErrContentNotObject},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bannerPatch, err := ValidateBannerPatch(strings.NewReader(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && bannerPatch == nil {
+				t.Error("patch is nil without error")
+			}
+		})
+	}
+}
+
+func TestValidateBannerPatchKeepsOmittedFields(t *testing.T) {
+	t.Parallel()
+
+	bannerPatch, err := ValidateBannerPatch(strings.NewReader(`{"tag_ids": [4, 5]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bannerPatch.TagIDs == nil || !slices.Equal(*bannerPatch.TagIDs, []uint64{4, 5}) {
+		t.Errorf("TagIDs = %v, want [4 5]", bannerPatch.TagIDs)
+	}
+
+	if bannerPatch.FeatureID != nil || bannerPatch.Content != nil || bannerPatch.IsActive != nil {
+		t.Errorf("omitted fields are set: %+v", bannerPatch)
+	}
+}
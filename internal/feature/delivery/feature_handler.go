@@ -0,0 +1,231 @@
+package delivery
+
+import (
+	"context"
+	featureusecases "github.com/SanExpett/banners-backend/internal/feature/usecases"
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/SanExpett/banners-backend/pkg/utils"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+var _ IFeatureService = (*featureusecases.FeatureService)(nil)
+
+type IFeatureService interface {
+	AddFeature(ctx context.Context, r io.Reader) (uint64, error)
+	GetFeature(ctx context.Context, featureID uint64) (*models.Feature, error)
+	GetFeaturesList(ctx context.Context, limit uint64, offset uint64) ([]*models.Feature, error)
+	UpdateFeature(ctx context.Context, featureID uint64, r io.Reader) error
+	DeleteFeature(ctx context.Context, featureID uint64, cascade bool) error
+}
+
+type FeatureHandler struct {
+	service IFeatureService
+	logger  *zap.SugaredLogger
+}
+
+func NewFeatureHandler(featureService IFeatureService) (*FeatureHandler, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeatureHandler{
+		service: featureService,
+		logger:  logger,
+	}, nil
+}
+
+// AddFeatureHandler godoc
+//
+//	@Summary    add feature
+//	@Description  add feature by data
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags Feature
+//
+//	@Accept      json
+//	@Produce    json
+//	@Param      feature  body models.PreFeature true  "feature data for adding"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} delivery.ResponseID
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature/add [post]
+func (f *FeatureHandler) AddFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	featureID, err := f.service.AddFeature(ctx, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger, delivery.NewResponseID(featureID))
+	f.logger.Infof("in AddFeatureHandler: added feature id= %+v", featureID)
+}
+
+// GetFeatureHandler godoc
+//
+//	@Summary    get feature
+//	@Description  get feature by id
+//	@Tags Feature
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "feature id"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} FeatureResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature/get [get]
+func (f *FeatureHandler) GetFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	featureID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	feature, err := f.service.GetFeature(ctx, featureID)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger, NewFeatureResponse(delivery.StatusResponseSuccessful, feature))
+	f.logger.Infof("in GetFeatureHandler: get feature: %+v", feature)
+}
+
+// GetFeaturesListHandler godoc
+//
+//	@Summary    get features list
+//	@Description  get features list ordered by id
+//	@Tags Feature
+//	@Accept      json
+//	@Produce    json
+//	@Param      limit  query uint64 false  "limit of features"
+//	@Param      offset  query uint64 false  "offset of features"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} FeatureListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature/get_list [get]
+func (f *FeatureHandler) GetFeaturesListHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limit, err := utils.ParseUint64FromRequest(r, "limit")
+	if err != nil {
+		limit = 10
+	}
+
+	offset, err := utils.ParseUint64FromRequest(r, "offset")
+	if err != nil {
+		offset = 0
+	}
+
+	features, err := f.service.GetFeaturesList(ctx, limit, offset)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger, NewFeatureListResponse(delivery.StatusResponseSuccessful, features))
+	f.logger.Infof("in GetFeaturesListHandler: get features list: %+v", features)
+}
+
+// UpdateFeatureHandler godoc
+//
+//	@Summary    update feature
+//	@Description  rename feature
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags Feature
+//
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "feature id"
+//	@Param      feature  body models.PreFeature true  "feature data for updating"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature/update [patch]
+func (f *FeatureHandler) UpdateFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	featureID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	err = f.service.UpdateFeature(ctx, featureID, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulUpdateFeature))
+	f.logger.Infof("in UpdateFeatureHandler: updated feature id=%d", featureID)
+}
+
+// DeleteFeatureHandler godoc
+//
+//	@Summary    delete feature
+//	@Description  delete feature with its content schemas. If banners reference feature, it is not deleted
+//	@Description  unless cascade=true, then these banners are deleted too. Recovery will be impossible.
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description StatusErrConflict        = 409 (body.details has feature_id and banners_count)
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags Feature
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "feature id"
+//	@Param      cascade  query bool false  "delete banners of feature too"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /feature/delete [delete]
+func (f *FeatureHandler) DeleteFeatureHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	featureID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	cascade := utils.ParseBoolFromRequest(r, "cascade")
+
+	err = f.service.DeleteFeature(ctx, featureID, cascade)
+	if err != nil {
+		delivery.HandleErr(w, r, f.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, f.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteFeature))
+	f.logger.Infof("in DeleteFeatureHandler: deleted feature id=%d cascade=%t", featureID, cascade)
+}
@@ -0,0 +1,32 @@
+package delivery
+
+import "github.com/SanExpett/banners-backend/pkg/models"
+
+const (
+	ResponseSuccessfulUpdateFeature = "Фича успешно обновлена"
+	ResponseSuccessfulDeleteFeature = "Фича успешно удалена"
+)
+
+type FeatureResponse struct {
+	Status int             `json:"status"`
+	Body   *models.Feature `json:"body"`
+}
+
+func NewFeatureResponse(status int, body *models.Feature) *FeatureResponse {
+	return &FeatureResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type FeatureListResponse struct {
+	Status int               `json:"status"`
+	Body   []*models.Feature `json:"body"`
+}
+
+func NewFeatureListResponse(status int, body []*models.Feature) *FeatureListResponse {
+	return &FeatureListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrFeatureNotFound = myerrors.NewNotFoundError("feature_not_found", myerrors.Message{
+		RU: "Эта фича не найдена",
+		EN: "Feature is not found",
+	})
+
+	MessageErrFeatureInUse = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Фичу используют баннеры, удалите их или удалите фичу вместе с ними (cascade=true)",
+		EN: "Feature is used by banners, delete them or delete feature with them (cascade=true)",
+	}
+)
+
+type FeatureStorage struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewFeatureStorage(pool *pgxpool.Pool) (*FeatureStorage, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &FeatureStorage{
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+func (f *FeatureStorage) AddFeature(ctx context.Context, preFeature *models.PreFeature) (uint64, error) {
+	SQLCreateFeature := `INSERT INTO public."feature" (title) VALUES ($1) RETURNING id`
+
+	var featureID uint64
+
+	featureRow := f.pool.QueryRow(ctx, SQLCreateFeature, preFeature.Title)
+	if err := featureRow.Scan(&featureID); err != nil {
+		f.logger.Errorf("in AddFeature: preFeature=%+v err=%+v", preFeature, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return featureID, nil
+}
+
+func (f *FeatureStorage) GetFeature(ctx context.Context, featureID uint64) (*models.Feature, error) {
+	SQLSelectFeature := `SELECT id, title, created_at FROM public."feature" WHERE id=$1`
+
+	feature := &models.Feature{} //nolint:exhaustruct
+
+	featureRow := f.pool.QueryRow(ctx, SQLSelectFeature, featureID)
+	if err := featureRow.Scan(&feature.ID, &feature.Title, &feature.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrFeatureNotFound)
+		}
+
+		f.logger.Errorf("error with featureID=%d: %+v", featureID, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return feature, nil
+}
+
+func (f *FeatureStorage) GetFeaturesList(ctx context.Context, limit uint64, offset uint64) ([]*models.Feature,
+	error) {
+	SQLSelectFeatures := `SELECT id, title, created_at FROM public."feature" ORDER BY id LIMIT $1 OFFSET $2`
+
+	featuresRows, err := f.pool.Query(ctx, SQLSelectFeatures, limit, offset)
+	if err != nil {
+		f.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curFeature := new(models.Feature)
+
+	var slFeatures []*models.Feature
+
+	_, err = pgx.ForEachRow(featuresRows, []any{
+		&curFeature.ID, &curFeature.Title, &curFeature.CreatedAt,
+	}, func() error {
+		slFeatures = append(slFeatures, &models.Feature{
+			ID:        curFeature.ID,
+			Title:     curFeature.Title,
+			CreatedAt: curFeature.CreatedAt,
+		})
+
+		return nil
+	})
+	if err != nil {
+		f.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slFeatures, nil
+}
+
+func (f *FeatureStorage) UpdateFeature(ctx context.Context, featureID uint64, preFeature *models.PreFeature) error {
+	SQLUpdateFeature := `UPDATE public."feature" SET title = $1 WHERE id = $2`
+
+	result, err := f.pool.Exec(ctx, SQLUpdateFeature, preFeature.Title, featureID)
+	if err != nil {
+		f.logger.Errorf("in UpdateFeature: preFeature=%+v err=%+v", preFeature, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrFeatureNotFound)
+	}
+
+	return nil
+}
+
+// lockFeature locks feature, so no banner can reference it until transaction ends.
+func (f *FeatureStorage) lockFeature(ctx context.Context, tx pgx.Tx, featureID uint64) error {
+	SQLLockFeature := `SELECT id FROM public."feature" WHERE id=$1 FOR UPDATE`
+
+	var id uint64
+
+	featureRow := tx.QueryRow(ctx, SQLLockFeature, featureID)
+	if err := featureRow.Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf(myerrors.ErrTemplate, ErrFeatureNotFound)
+		}
+
+		f.logger.Errorf("error with featureID=%d: %+v", featureID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (f *FeatureStorage) countBanners(ctx context.Context, tx pgx.Tx, featureID uint64) (uint64, error) {
+	SQLCountBanners := `SELECT COUNT(*) FROM public."banner" WHERE feature_id=$1`
+
+	var bannersCount uint64
+
+	countRow := tx.QueryRow(ctx, SQLCountBanners, featureID)
+	if err := countRow.Scan(&bannersCount); err != nil {
+		f.logger.Errorf("error with featureID=%d: %+v", featureID, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannersCount, nil
+}
+
+// DeleteFeature deletes feature with its schemas. If banners reference feature, it is
+// refused with conflict error, or, if cascade is set, these banners are deleted too.
+func (f *FeatureStorage) DeleteFeature(ctx context.Context, featureID uint64, cascade bool) error {
+	SQLDeleteBanners := `DELETE FROM public."banner" WHERE feature_id=$1`
+	SQLDeleteFeature := `DELETE FROM public."feature" WHERE id=$1`
+
+	err := pgx.BeginFunc(ctx, f.pool, func(tx pgx.Tx) error {
+		err := f.lockFeature(ctx, tx, featureID)
+		if err != nil {
+			return err
+		}
+
+		bannersCount, err := f.countBanners(ctx, tx, featureID)
+		if err != nil {
+			return err
+		}
+
+		if bannersCount != 0 {
+			if !cascade {
+				return myerrors.NewConflictError(models.FeatureInUse{FeatureID: featureID, BannersCount: bannersCount},
+					"feature_in_use", MessageErrFeatureInUse)
+			}
+
+			_, err = tx.Exec(ctx, SQLDeleteBanners, featureID)
+			if err != nil {
+				return fmt.Errorf(myerrors.ErrTemplate, err)
+			}
+		}
+
+		_, err = tx.Exec(ctx, SQLDeleteFeature, featureID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		f.logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
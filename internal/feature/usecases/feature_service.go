@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	featurerepo "github.com/SanExpett/banners-backend/internal/feature/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"go.uber.org/zap"
+	"io"
+)
+
+var _ IFeatureStorage = (*featurerepo.FeatureStorage)(nil)
+
+type IFeatureStorage interface {
+	AddFeature(ctx context.Context, preFeature *models.PreFeature) (uint64, error)
+	GetFeature(ctx context.Context, featureID uint64) (*models.Feature, error)
+	GetFeaturesList(ctx context.Context, limit uint64, offset uint64) ([]*models.Feature, error)
+	UpdateFeature(ctx context.Context, featureID uint64, preFeature *models.PreFeature) error
+	DeleteFeature(ctx context.Context, featureID uint64, cascade bool) error
+}
+
+type FeatureService struct {
+	storage IFeatureStorage
+	logger  *zap.SugaredLogger
+}
+
+func NewFeatureService(featureStorage IFeatureStorage) (*FeatureService, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &FeatureService{storage: featureStorage, logger: logger}, nil
+}
+
+func (f *FeatureService) AddFeature(ctx context.Context, r io.Reader) (uint64, error) {
+	preFeature, err := ValidatePreFeature(r)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	featureID, err := f.storage.AddFeature(ctx, preFeature)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return featureID, nil
+}
+
+func (f *FeatureService) GetFeature(ctx context.Context, featureID uint64) (*models.Feature, error) {
+	feature, err := f.storage.GetFeature(ctx, featureID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return feature, nil
+}
+
+func (f *FeatureService) GetFeaturesList(ctx context.Context, limit uint64, offset uint64) ([]*models.Feature,
+	error) {
+	features, err := f.storage.GetFeaturesList(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return features, nil
+}
+
+func (f *FeatureService) UpdateFeature(ctx context.Context, featureID uint64, r io.Reader) error {
+	preFeature, err := ValidatePreFeature(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = f.storage.UpdateFeature(ctx, featureID, preFeature)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// DeleteFeature deletes feature. Banners of feature are deleted too if cascade is set,
+// otherwise feature with banners is not deleted. Caches of banners are invalidated
+// by notification of banners change.
+func (f *FeatureService) DeleteFeature(ctx context.Context, featureID uint64, cascade bool) error {
+	err := f.storage.DeleteFeature(ctx, featureID, cascade)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/asaskevich/govalidator"
+	"io"
+)
+
+var (
+	ErrDecodePreFeature = myerrors.NewError("bad_feature_json", myerrors.Message{
+		RU: "Некорректный json фичи",
+		EN: "Invalid json of feature",
+	})
+	ErrWrongFeature = myerrors.NewError("wrong_feature", myerrors.Message{
+		RU: "Название фичи должно быть длиной от 1 до 150 символов",
+		EN: "Title of feature must be from 1 to 150 characters long",
+	})
+)
+
+func ValidatePreFeature(r io.Reader) (*models.PreFeature, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	decoder := json.NewDecoder(r)
+
+	preFeature := new(models.PreFeature)
+	if err := decoder.Decode(preFeature); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreFeature)
+	}
+
+	preFeature.Trim()
+
+	_, err = govalidator.ValidateStruct(preFeature)
+	if err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongFeature)
+	}
+
+	return preFeature, nil
+}
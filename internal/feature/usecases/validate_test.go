@@ -0,0 +1,58 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if _, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestValidatePreFeature(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		body      string
+		wantTitle string
+		wantErr   error
+	}{
+		{name: "valid title", body: `{"title": "promo"}`, wantTitle: "promo"},
+		{name: "title is trimmed", body: `{"title": "  promo \n"}`, wantTitle: "promo"},
+		{
+			name:      "longest title in runes",
+			body:      `{"title": "` + strings.Repeat("ф", 150) + `"}`,
+			wantTitle: strings.Repeat("ф", 150),
+		},
+		{name: "too long title", body: `{"title": "` + strings.Repeat("ф", 151) + `"}`, wantErr: ErrWrongFeature},
+		{name: "empty title", body: `{"title": ""}`, wantErr: ErrWrongFeature},
+		{name: "only spaces", body: `{"title": "   "}`, wantErr: ErrWrongFeature},
+		{name: "no title", body: `{}`, wantErr: ErrWrongFeature},
+		{name: "bad json", body: `{"title": `, wantErr: ErrDecodePreFeature},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			preFeature, err := ValidatePreFeature(strings.NewReader(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && preFeature.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", preFeature.Title, tt.wantTitle)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
 	"go.uber.org/zap"
 	"net/http"
+	"sync/atomic"
 )
 
 const (
@@ -14,6 +15,10 @@ const (
 	StatusResponseSuccessful      = 200
 	StatusRedirectAfterSuccessful = 303
 	StatusErrBadRequest           = 400
+	StatusErrUnauthorized         = 401
+	StatusErrForbidden            = 403
+	StatusErrNotFound             = 404
+	StatusErrConflict             = 409
 	StatusErrInternalServer       = 500
 )
 
@@ -22,14 +27,33 @@ const (
 )
 
 var (
-	ErrAuthHeaderNotPresented = myerrors.NewError("Должен быть выставлен Authorization header, а его нет")
-	ErrNotAdmin               = myerrors.NewError("Это действие может выполнять только администратор")
+	ErrInternal = myerrors.NewInternalError("internal_error", myerrors.Message{
+		RU: ErrInternalServer,
+		EN: "Internal server error",
+	})
+	ErrAuthHeaderNotPresented = myerrors.NewUnauthorizedError("auth_header_required", myerrors.Message{
+		RU: "Должен быть выставлен Authorization header, а его нет",
+		EN: "Authorization header is required",
+	})
+	ErrNotAdmin = myerrors.NewForbiddenError("admin_required", myerrors.Message{
+		RU: "Это действие может выполнять только администратор",
+		EN: "Only admin can do this action",
+	})
 )
 
 const (
 	CookieAuthName = "access_token"
 )
 
+// legacyErrorStatus makes SendErrResponse write HTTPStatusError instead of status of error,
+// for old clients which look only at status in body.
+var legacyErrorStatus atomic.Bool //nolint:gochecknoglobals
+
+// SetLegacyErrorStatus turns on or off answering all errors with HTTPStatusError.
+func SetLegacyErrorStatus(enabled bool) {
+	legacyErrorStatus.Store(enabled)
+}
+
 type ResponseBody struct {
 	Message string `json:"message"`
 }
@@ -60,7 +84,11 @@ func NewResponseID(ID uint64) *ResponseID {
 }
 
 type ResponseBodyError struct {
-	Error string `json:"error"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+	// Error duplicates Message, it is sent only with legacy error status for old clients
+	Error string `json:"error,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -68,10 +96,10 @@ type ErrorResponse struct {
 	Body   ResponseBodyError `json:"body"`
 }
 
-func NewErrResponse(status int, err string) *ErrorResponse {
+func NewErrResponse(status int, code string, message string, details any) *ErrorResponse {
 	return &ErrorResponse{
 		Status: status,
-		Body:   ResponseBodyError{Error: err},
+		Body:   ResponseBodyError{Code: code, Message: message, Details: details}, //nolint:exhaustruct
 	}
 }
 
@@ -91,9 +119,17 @@ func sendResponse(w http.ResponseWriter, logger *zap.SugaredLogger, response any
 	}
 }
 
-func SendErrResponse(w http.ResponseWriter, logger *zap.SugaredLogger, response any) {
+// SendErrResponse writes response with HTTP status equal to response.Status,
+// or with HTTPStatusError and message duplicated to error field if legacy error status is on.
+func SendErrResponse(w http.ResponseWriter, logger *zap.SugaredLogger, response *ErrorResponse) {
+	status := response.Status
+	if legacyErrorStatus.Load() {
+		status = HTTPStatusError
+		response.Body.Error = response.Body.Message
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(HTTPStatusError)
+	w.WriteHeader(status)
 	sendResponse(w, logger, response)
 }
 
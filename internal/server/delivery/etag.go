@@ -0,0 +1,48 @@
+package delivery
+
+import (
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrIfMatchRequired = myerrors.NewError("if_match_required", myerrors.Message{
+		RU: "Должен быть выставлен If-Match header с ревизией ресурса",
+		EN: "If-Match header with revision of resource is required",
+	})
+	ErrBadIfMatch = myerrors.NewError("bad_if_match", myerrors.Message{
+		RU: "If-Match header должен содержать ревизию ресурса, например \"3\"",
+		EN: "If-Match header must contain revision of resource, for example \"3\"",
+	})
+	ErrWeakIfMatch = myerrors.NewError("weak_if_match", myerrors.Message{
+		RU: "If-Match header не может содержать слабый ETag (W/), передайте ревизию ресурса, например \"3\"",
+		EN: "If-Match header must not contain weak ETag (W/), pass revision of resource, for example \"3\"",
+	})
+)
+
+// GetRevisionFromIfMatch parses revision from If-Match header. Only strong ETag ("3") is accepted:
+// If-Match is compared strongly, so weak ETag (W/"3") never matches and is rejected.
+func GetRevisionFromIfMatch(r *http.Request) (uint64, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" {
+		return 0, ErrIfMatchRequired
+	}
+
+	if strings.HasPrefix(ifMatch, "W/") {
+		return 0, ErrWeakIfMatch
+	}
+
+	revision, err := strconv.ParseUint(strings.Trim(ifMatch, `"`), 10, 64)
+	if err != nil || revision == 0 {
+		return 0, ErrBadIfMatch
+	}
+
+	return revision, nil
+}
+
+// SetETag exposes revision of resource as its ETag.
+func SetETag(w http.ResponseWriter, revision uint64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatUint(revision, 10)))
+}
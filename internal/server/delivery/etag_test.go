@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetRevisionFromIfMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    uint64
+		wantErr error
+	}{
+		{name: "strong etag", ifMatch: `"3"`, want: 3},
+		{name: "weak etag", ifMatch: `W/"12"`, wantErr: ErrWeakIfMatch},
+		{name: "unquoted revision", ifMatch: `7`, want: 7},
+		{name: "spaces around", ifMatch: ` "5" `, want: 5},
+		{name: "no header", ifMatch: "", wantErr: ErrIfMatchRequired},
+		{name: "only spaces", ifMatch: "   ", wantErr: ErrIfMatchRequired},
+		{name: "zero revision", ifMatch: `"0"`, wantErr: ErrBadIfMatch},
+		{name: "negative revision", ifMatch: `"-1"`, wantErr: ErrBadIfMatch},
+		{name: "not a number", ifMatch: `"abc"`, wantErr: ErrBadIfMatch},
+		{name: "any etag", ifMatch: `*`, wantErr: ErrBadIfMatch},
+		{name: "several etags", ifMatch: `"1", "2"`, wantErr: ErrBadIfMatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPatch, "/api/v1/banner/1", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, err := GetRevisionFromIfMatch(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("revision = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetETag(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	SetETag(w, 42)
+
+	r := httptest.NewRequest(http.MethodPatch, "/api/v1/banner/1", nil)
+	r.Header.Set("If-Match", w.Header().Get("ETag"))
+
+	if got := w.Header().Get("ETag"); got != `"42"` {
+		t.Fatalf("ETag = %s, want %s", got, `"42"`)
+	}
+
+	if got, err := GetRevisionFromIfMatch(r); err != nil || got != 42 {
+		t.Errorf("revision from ETag = %d, %v, want 42", got, err)
+	}
+}
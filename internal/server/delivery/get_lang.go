@@ -0,0 +1,42 @@
+package delivery
+
+import (
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetLangFromRequest returns the most preferred by Accept-Language header of supported
+// languages, or myerrors.DefaultLang if header does not name any of them.
+func GetLangFromRequest(r *http.Request) myerrors.Lang {
+	lang := myerrors.DefaultLang
+	bestWeight := 0.0
+
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		weight := 1.0
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsedWeight, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+
+			weight = parsedWeight
+		}
+
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+
+		switch candidate := myerrors.Lang(primary); candidate {
+		case myerrors.LangRU, myerrors.LangEN:
+			if weight > bestWeight {
+				lang = candidate
+				bestWeight = weight
+			}
+		}
+	}
+
+	return lang
+}
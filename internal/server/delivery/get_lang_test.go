@@ -0,0 +1,46 @@
+package delivery
+
+import (
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetLangFromRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           myerrors.Lang
+	}{
+		{name: "no header", acceptLanguage: "", want: myerrors.DefaultLang},
+		{name: "single language", acceptLanguage: "en", want: myerrors.LangEN},
+		{name: "region is ignored", acceptLanguage: "en-GB", want: myerrors.LangEN},
+		{name: "case is ignored", acceptLanguage: "EN-us", want: myerrors.LangEN},
+		{name: "first of equal weights", acceptLanguage: "ru, en", want: myerrors.LangRU},
+		{name: "higher q wins", acceptLanguage: "ru;q=0.3, en;q=0.8", want: myerrors.LangEN},
+		{name: "implicit q is 1", acceptLanguage: "en;q=0.9, ru", want: myerrors.LangRU},
+		{name: "unsupported are skipped", acceptLanguage: "de, fr;q=0.9, en;q=0.1", want: myerrors.LangEN},
+		{name: "only unsupported", acceptLanguage: "de, fr", want: myerrors.DefaultLang},
+		{name: "q=0 is not acceptable", acceptLanguage: "en;q=0", want: myerrors.DefaultLang},
+		{name: "bad q is skipped", acceptLanguage: "en;q=abc, ru;q=0.2", want: myerrors.LangRU},
+		{name: "spaces around params", acceptLanguage: " en-US ; q=0.7 , ru ; q=0.6", want: myerrors.LangEN},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.acceptLanguage)
+
+			if got := GetLangFromRequest(r); got != tt.want {
+				t.Errorf("GetLangFromRequest(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
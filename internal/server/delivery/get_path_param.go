@@ -1,12 +1,40 @@
 package delivery
 
-import "strings"
+import (
+	"context"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"net/http"
+	"strconv"
+)
 
-func GetPathParam(path string) string {
-	last := strings.LastIndex(path, "/")
-	if last == -1 {
-		return ""
+type pathParamsKey struct{}
+
+var (
+	MessageErrWrongPathParam = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Получили некорректный параметр пути. Он должен быть целым неотрицательным числом: %s=%s",
+		EN: "Got invalid path parameter. It must be a non-negative integer: %s=%s",
+	}
+)
+
+// WithPathParams returns ctx carrying params of path matched by router.
+func WithPathParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, pathParamsKey{}, params)
+}
+
+// GetPathParam returns param of path named as {name} in route pattern or "" if there is none.
+func GetPathParam(r *http.Request, name string) string {
+	params, _ := r.Context().Value(pathParamsKey{}).(map[string]string)
+
+	return params[name]
+}
+
+func GetPathParamUint64(r *http.Request, name string) (uint64, error) {
+	value := GetPathParam(r, name)
+
+	number, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, myerrors.NewError("wrong_path_param", MessageErrWrongPathParam, name, value)
 	}
 
-	return path[last+1:]
+	return number, nil
 }
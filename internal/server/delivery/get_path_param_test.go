@@ -0,0 +1,30 @@
+package delivery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetPathParamUint64(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/banner/42", nil)
+	r = r.WithContext(WithPathParams(r.Context(), map[string]string{"id": "42", "tag_id": "-1"}))
+
+	if id, err := GetPathParamUint64(r, "id"); err != nil || id != 42 {
+		t.Errorf("GetPathParamUint64(id) = %d, %v, want 42, nil", id, err)
+	}
+
+	if _, err := GetPathParamUint64(r, "tag_id"); err == nil {
+		t.Error("negative param is accepted")
+	}
+
+	if _, err := GetPathParamUint64(r, "version"); err == nil {
+		t.Error("missing param is accepted")
+	}
+
+	if got := GetPathParam(httptest.NewRequest(http.MethodGet, "/", nil), "id"); got != "" {
+		t.Errorf("GetPathParam without params = %q, want empty", got)
+	}
+}
@@ -7,13 +7,15 @@ import (
 	"net/http"
 )
 
-func HandleErr(w http.ResponseWriter, logger *zap.SugaredLogger, err error) {
+// HandleErr sends err to client with its code and message in language of request.
+// Errors which are not myerrors.Error are hidden behind ErrInternal.
+func HandleErr(w http.ResponseWriter, r *http.Request, logger *zap.SugaredLogger, err error) {
 	myErr := &myerrors.Error{}
-	if errors.As(err, &myErr) {
-		SendErrResponse(w, logger, NewErrResponse(StatusErrBadRequest, err.Error()))
-
-		return
+	if !errors.As(err, &myErr) {
+		myErr = ErrInternal
 	}
 
-	SendErrResponse(w, logger, NewErrResponse(StatusErrInternalServer, ErrInternalServer))
+	w.Header().Add("Vary", "Accept-Language")
+	SendErrResponse(w, logger, NewErrResponse(myErr.HTTPStatus(), myErr.Code(),
+		myErr.Message(GetLangFromRequest(r)), myErr.Details))
 }
@@ -0,0 +1,178 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testErrResponse struct {
+	Status int `json:"status"`
+	Body   struct {
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Details json.RawMessage `json:"details"`
+		Error   string          `json:"error"`
+	} `json:"body"`
+}
+
+func handleTestErr(t *testing.T, err error, acceptLanguage string) (*httptest.ResponseRecorder, *testErrResponse) {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", acceptLanguage)
+
+	w := httptest.NewRecorder()
+	HandleErr(w, r, zap.NewNop().Sugar(), err)
+
+	response := &testErrResponse{} //nolint:exhaustruct
+	if err := json.Unmarshal(w.Body.Bytes(), response); err != nil {
+		t.Fatal(err)
+	}
+
+	return w, response
+}
+
+func TestHandleErr(t *testing.T) {
+	t.Parallel()
+
+	conflicts := []models.BannerConflict{{BannerID: 1, FeatureID: 2, TagID: 3}}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+		wantDetails string
+	}{
+		{
+			name: "conflict with details",
+			err: fmt.Errorf(myerrors.ErrTemplate, myerrors.NewConflictError(conflicts, "pairs_taken",
+				myerrors.Message{RU: "пары заняты", EN: "pairs are taken"})),
+			wantStatus:  http.StatusConflict,
+			wantCode:    "pairs_taken",
+			wantMessage: "pairs are taken",
+			wantDetails: `[{"banner_id":1,"feature_id":2,"tag_id":3}]`,
+		},
+		{
+			name: "validation error with details",
+			err: fmt.Errorf(myerrors.ErrTemplate, myerrors.NewValidationError(
+				[]models.ContentValidationError{{Path: "/title", Message: "expected string"}}, "bad_content",
+				myerrors.Message{RU: "плохой контент", EN: "bad content"})),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "bad_content",
+			wantMessage: "bad content",
+			wantDetails: `[{"path":"/title","message":"expected string"}]`,
+		},
+		{
+			name: "bad request with args",
+			err: fmt.Errorf(myerrors.ErrTemplate, myerrors.NewError("bad_param",
+				myerrors.Message{RU: "плохой параметр %s", EN: "bad param %s"}, "id")),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "bad_param",
+			wantMessage: "bad param id",
+		},
+		{
+			name: "not found",
+			err: fmt.Errorf(myerrors.ErrTemplate, myerrors.NewNotFoundError("banner_not_found",
+				myerrors.Message{RU: "нет баннера", EN: "no banner"})),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "banner_not_found",
+			wantMessage: "no banner",
+		},
+		{
+			name:        "unauthorized",
+			err:         fmt.Errorf(myerrors.ErrTemplate, ErrAuthHeaderNotPresented),
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    ErrAuthHeaderNotPresented.Code(),
+			wantMessage: ErrAuthHeaderNotPresented.Message(myerrors.LangEN),
+		},
+		{
+			name:        "forbidden",
+			err:         fmt.Errorf(myerrors.ErrTemplate, ErrNotAdmin),
+			wantStatus:  http.StatusForbidden,
+			wantCode:    ErrNotAdmin.Code(),
+			wantMessage: ErrNotAdmin.Message(myerrors.LangEN),
+		},
+		{
+			name:        "internal error is hidden",
+			err:         errors.New("connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    ErrInternal.Code(),
+			wantMessage: ErrInternal.Message(myerrors.LangEN),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w, response := handleTestErr(t, tt.err, "en")
+
+			if w.Code != tt.wantStatus || response.Status != tt.wantStatus {
+				t.Errorf("HTTP status = %d, status in body = %d, want %d", w.Code, response.Status, tt.wantStatus)
+			}
+
+			if response.Body.Code != tt.wantCode || response.Body.Message != tt.wantMessage {
+				t.Errorf("body = %q %q, want %q %q",
+					response.Body.Code, response.Body.Message, tt.wantCode, tt.wantMessage)
+			}
+
+			if string(response.Body.Details) != tt.wantDetails {
+				t.Errorf("details = %s, want %s", response.Body.Details, tt.wantDetails)
+			}
+
+			if response.Body.Error != "" {
+				t.Errorf("legacy error field is sent: %q", response.Body.Error)
+			}
+
+			if w.Header().Get("Vary") != "Accept-Language" {
+				t.Errorf("Vary = %q, want Accept-Language", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestHandleErrLanguage(t *testing.T) {
+	t.Parallel()
+
+	err := myerrors.NewNotFoundError("banner_not_found", myerrors.Message{RU: "нет баннера", EN: "no banner"})
+
+	if _, response := handleTestErr(t, err, ""); response.Body.Message != "нет баннера" {
+		t.Errorf("message without Accept-Language = %q, want russian", response.Body.Message)
+	}
+
+	if _, response := handleTestErr(t, err, "en-US,ru;q=0.5"); response.Body.Message != "no banner" {
+		t.Errorf("message for en-US = %q, want english", response.Body.Message)
+	}
+}
+
+// TestHandleErrLegacyStatus is not parallel, because it switches the global legacy flag.
+func TestHandleErrLegacyStatus(t *testing.T) {
+	SetLegacyErrorStatus(true)
+	defer SetLegacyErrorStatus(false)
+
+	w, response := handleTestErr(t, myerrors.NewNotFoundError("banner_not_found",
+		myerrors.Message{RU: "нет баннера", EN: "no banner"}), "en")
+
+	if w.Code != HTTPStatusError {
+		t.Errorf("HTTP status = %d, want %d", w.Code, HTTPStatusError)
+	}
+
+	if response.Status != http.StatusNotFound {
+		t.Errorf("status in body = %d, want %d", response.Status, http.StatusNotFound)
+	}
+
+	if response.Body.Error != "no banner" {
+		t.Errorf("error = %q, want message duplicated", response.Body.Error)
+	}
+}
@@ -2,10 +2,13 @@ package mux
 
 import (
 	"context"
+	"github.com/SanExpett/banners-backend/pkg/jwt"
 	"github.com/SanExpett/banners-backend/pkg/middleware"
 	"net/http"
 
 	bannerdelivery "github.com/SanExpett/banners-backend/internal/banner/delivery"
+	featuredelivery "github.com/SanExpett/banners-backend/internal/feature/delivery"
+	tagdelivery "github.com/SanExpett/banners-backend/internal/tag/delivery"
 	userdelivery "github.com/SanExpett/banners-backend/internal/user/delivery"
 
 	"go.uber.org/zap"
@@ -26,11 +29,11 @@ func NewConfigMux(addrOrigin string, schema string, portServer string) *ConfigMu
 }
 
 func NewMux(ctx context.Context, configMux *ConfigMux, userService userdelivery.IUserService,
-	bannerService bannerdelivery.IBannerService, logger *zap.SugaredLogger,
+	bannerService bannerdelivery.IBannerService, featureService featuredelivery.IFeatureService,
+	tagService tagdelivery.ITagService, tokens *jwt.Manager, revokedTokens middleware.IRevokedTokens,
+	logger *zap.SugaredLogger,
 ) (http.Handler, error) {
-	router := http.NewServeMux()
-
-	userHandler, err := userdelivery.NewUserHandler(userService)
+	userHandler, err := userdelivery.NewUserHandler(userService, tokens)
 	if err != nil {
 		return nil, err
 	}
@@ -40,24 +43,77 @@ func NewMux(ctx context.Context, configMux *ConfigMux, userService userdelivery.
 		return nil, err
 	}
 
-	router.Handle("/api/v1/signup", middleware.Context(ctx,
-		middleware.SetupCORS(userHandler.SignUpHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/signin", middleware.Context(ctx,
-		middleware.SetupCORS(userHandler.SignInHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/logout", middleware.Context(ctx,
-		middleware.SetupCORS(userHandler.LogOutHandler, configMux.addrOrigin, configMux.schema)))
+	featureHandler, err := featuredelivery.NewFeatureHandler(featureService)
+	if err != nil {
+		return nil, err
+	}
+
+	tagHandler, err := tagdelivery.NewTagHandler(tagService)
+	if err != nil {
+		return nil, err
+	}
+
+	// public routes are open for everyone, authorized need valid token and admin need token of admin.
+	// Banner routes are authorized, permissions of user on banners are checked by banner handlers
+	public := func(handler http.HandlerFunc) http.Handler {
+		return middleware.SetupCORS(handler, configMux.addrOrigin, configMux.schema)
+	}
+	authorized := func(handler http.HandlerFunc) http.Handler {
+		return public(middleware.Auth(handler, tokens, revokedTokens, logger))
+	}
+	admin := func(handler http.HandlerFunc) http.Handler {
+		return authorized(middleware.RequireAdmin(handler, logger))
+	}
+
+	// 404 and 405 of router itself are public too, so browser gets them instead of CORS error
+	router := NewRouter(public)
+
+	router.Handle(http.MethodPost, "/api/v1/signup", public(userHandler.SignUpHandler))
+	router.Handle(http.MethodGet, "/api/v1/signin", public(userHandler.SignInHandler))
+	router.Handle(http.MethodPost, "/api/v1/refresh", public(userHandler.RefreshHandler))
+	router.Handle(http.MethodPost, "/api/v1/logout", authorized(userHandler.LogOutHandler))
+	router.Handle(http.MethodPost, "/api/v1/logout_all", authorized(userHandler.LogOutEverywhereHandler))
+	router.Handle(http.MethodGet, "/.well-known/jwks.json", public(userHandler.JwksHandler))
+	router.Handle(http.MethodPost, "/api/v1/user/set_admin", admin(userHandler.SetAdminHandler))
+	router.Handle(http.MethodGet, "/api/v1/role/get_list", admin(userHandler.GetRolesListHandler))
+	router.Handle(http.MethodPost, "/api/v1/role_binding/add", admin(userHandler.AddRoleBindingHandler))
+	router.Handle(http.MethodGet, "/api/v1/role_binding/get_list", admin(userHandler.GetRoleBindingsListHandler))
+	router.Handle(http.MethodDelete, "/api/v1/role_binding/delete", admin(userHandler.DeleteRoleBindingHandler))
+
+	router.Handle(http.MethodPost, "/api/v1/banner/add", authorized(bannerHandler.AddBannerHandler))
+	router.Handle(http.MethodGet, "/api/v1/banner/get", authorized(bannerHandler.GetBannerHandler))
+	router.Handle(http.MethodGet, "/api/v1/user_banner", authorized(bannerHandler.GetUserBannerHandler))
+	router.Handle(http.MethodDelete, "/api/v1/banner/{id}", authorized(bannerHandler.DeleteBannerHandler))
+	router.Handle(http.MethodPatch, "/api/v1/banner/{id}", authorized(bannerHandler.UpdateBannerHandler))
+	router.Handle(http.MethodPost, "/api/v1/banner/transfer", authorized(bannerHandler.TransferBannerHandler))
+	router.Handle(http.MethodGet, "/api/v1/banner/get_list", authorized(bannerHandler.GetBannersListHandler))
+	router.Handle(http.MethodPost, "/api/v1/banner/delete_by", authorized(bannerHandler.AddBannerDeleteJobHandler))
+	router.Handle(http.MethodGet, "/api/v1/banner/delete_job", authorized(bannerHandler.GetBannerDeleteJobHandler))
+
+	router.Handle(http.MethodGet, "/api/v1/banner/versions", authorized(bannerHandler.GetBannerVersionsHandler))
+	router.Handle(http.MethodGet, "/api/v1/banner/version", authorized(bannerHandler.GetBannerVersionHandler))
+	router.Handle(http.MethodPost, "/api/v1/banner/restore", authorized(bannerHandler.RestoreBannerVersionHandler))
+	router.Handle(http.MethodGet, "/api/v1/banner/cache_stats", admin(bannerHandler.GetBannerCacheStatsHandler))
+
+	router.Handle(http.MethodPost, "/api/v1/feature_schema/add", admin(bannerHandler.AddFeatureSchemaHandler))
+	router.Handle(http.MethodGet, "/api/v1/feature_schema/get", admin(bannerHandler.GetFeatureSchemaHandler))
+	router.Handle(http.MethodGet, "/api/v1/feature_schema/get_list", admin(bannerHandler.GetFeatureSchemasListHandler))
+	router.Handle(http.MethodPost, "/api/v1/feature_schema/check", admin(bannerHandler.CheckFeatureSchemaHandler))
+
+	router.Handle(http.MethodPost, "/api/v1/feature/add", admin(featureHandler.AddFeatureHandler))
+	router.Handle(http.MethodGet, "/api/v1/feature/get", admin(featureHandler.GetFeatureHandler))
+	router.Handle(http.MethodGet, "/api/v1/feature/get_list", admin(featureHandler.GetFeaturesListHandler))
+	router.Handle(http.MethodPatch, "/api/v1/feature/update", admin(featureHandler.UpdateFeatureHandler))
+	router.Handle(http.MethodDelete, "/api/v1/feature/delete", admin(featureHandler.DeleteFeatureHandler))
 
-	router.Handle("/api/v1/banner/add", middleware.Context(ctx,
-		middleware.SetupCORS(bannerHandler.AddBannerHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/banner/get", middleware.Context(ctx,
-		middleware.SetupCORS(bannerHandler.GetBannerHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/banner/delete", middleware.Context(ctx,
-		middleware.SetupCORS(bannerHandler.DeleteBannerHandler, configMux.addrOrigin, configMux.schema)))
-	router.Handle("/api/v1/banner/get_list", middleware.Context(ctx,
-		middleware.SetupCORS(bannerHandler.GetBannersListHandler, configMux.addrOrigin, configMux.schema)))
+	router.Handle(http.MethodPost, "/api/v1/tag/add", admin(tagHandler.AddTagHandler))
+	router.Handle(http.MethodGet, "/api/v1/tag/get", admin(tagHandler.GetTagHandler))
+	router.Handle(http.MethodGet, "/api/v1/tag/get_list", admin(tagHandler.GetTagsListHandler))
+	router.Handle(http.MethodPatch, "/api/v1/tag/update", admin(tagHandler.UpdateTagHandler))
+	router.Handle(http.MethodDelete, "/api/v1/tag/delete", admin(tagHandler.DeleteTagHandler))
 
 	mux := http.NewServeMux()
-	mux.Handle("/", middleware.Panic(router, logger))
+	mux.Handle("/", middleware.Panic(middleware.Context(ctx, router), logger))
 
 	return mux, nil
 }
@@ -0,0 +1,148 @@
+package mux
+
+import (
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type route struct {
+	method   string
+	segments []string
+	// staticCount is how many segments of pattern are not params, more specific routes win
+	staticCount int
+	handler     http.Handler
+}
+
+// Router dispatches requests by method and path. Segment of pattern written as {name}
+// matches any single segment of path, handlers read it with delivery.GetPathParam.
+// If path matches, but method does not, Router answers 405 with Allow header.
+// OPTIONS is answered by handler of any route of path, so CORS preflight works.
+type Router struct {
+	routes []*route
+	// wrap is applied to 404 and 405 answered by Router itself, so they get the same headers
+	// as answers of routes, e.g. CORS. Nil wrap leaves them as is.
+	wrap func(http.HandlerFunc) http.Handler
+}
+
+func NewRouter(wrap func(http.HandlerFunc) http.Handler) *Router {
+	return &Router{routes: nil, wrap: wrap}
+}
+
+// Handle registers handler for method and pattern like /api/v1/banner/{id}.
+func (rt *Router) Handle(method string, pattern string, handler http.Handler) {
+	segments := splitPath(pattern)
+
+	staticCount := 0
+
+	for _, segment := range segments {
+		if !isParamSegment(segment) {
+			staticCount++
+		}
+	}
+
+	rt.routes = append(rt.routes, &route{
+		method:      method,
+		segments:    segments,
+		staticCount: staticCount,
+		handler:     handler,
+	})
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pathSegments := splitPath(r.URL.Path)
+
+	var matched []*route
+
+	maxStaticCount := -1
+
+	for _, curRoute := range rt.routes {
+		if !curRoute.matchPath(pathSegments) || curRoute.staticCount < maxStaticCount {
+			continue
+		}
+
+		if curRoute.staticCount > maxStaticCount {
+			maxStaticCount = curRoute.staticCount
+			matched = matched[:0]
+		}
+
+		matched = append(matched, curRoute)
+	}
+
+	if len(matched) == 0 {
+		rt.serveOwn(w, r, http.NotFound)
+
+		return
+	}
+
+	for _, curRoute := range matched {
+		if curRoute.method == r.Method || r.Method == http.MethodOptions {
+			ctx := delivery.WithPathParams(r.Context(), curRoute.params(pathSegments))
+			curRoute.handler.ServeHTTP(w, r.WithContext(ctx))
+
+			return
+		}
+	}
+
+	w.Header().Set("Allow", allowedMethods(matched))
+	rt.serveOwn(w, r, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+	})
+}
+
+func (rt *Router) serveOwn(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+	if rt.wrap == nil {
+		handler(w, r)
+
+		return
+	}
+
+	rt.wrap(handler).ServeHTTP(w, r)
+}
+
+func (ro *route) matchPath(pathSegments []string) bool {
+	if len(ro.segments) != len(pathSegments) {
+		return false
+	}
+
+	for i, segment := range ro.segments {
+		if !isParamSegment(segment) && segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ro *route) params(pathSegments []string) map[string]string {
+	params := make(map[string]string)
+
+	for i, segment := range ro.segments {
+		if isParamSegment(segment) {
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+		}
+	}
+
+	return params
+}
+
+func allowedMethods(routes []*route) string {
+	methods := []string{http.MethodOptions}
+
+	for _, curRoute := range routes {
+		methods = append(methods, curRoute.method)
+	}
+
+	sort.Strings(methods)
+
+	return strings.Join(methods, ", ")
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParamSegment(segment string) bool {
+	return len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
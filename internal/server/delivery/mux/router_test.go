@@ -0,0 +1,140 @@
+package mux
+
+import (
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const headerRoute = "X-Route"
+
+// namedHandler answers with name of route and path param id, so test sees which route was chosen.
+func namedHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRoute, name+":"+delivery.GetPathParam(r, "id"))
+	})
+}
+
+func newTestRouter() *Router {
+	router := NewRouter(func(handler http.HandlerFunc) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Allow-Origin", "test")
+			handler(w, r)
+		})
+	})
+
+	router.Handle(http.MethodGet, "/api/v1/banner/get", namedHandler("get"))
+	router.Handle(http.MethodPatch, "/api/v1/banner/{id}", namedHandler("update"))
+	router.Handle(http.MethodDelete, "/api/v1/banner/{id}", namedHandler("delete"))
+	router.Handle(http.MethodPost, "/api/v1/banner/transfer", namedHandler("transfer"))
+
+	return router
+}
+
+func TestRouter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantRoute  string
+		wantAllow  string
+	}{
+		{
+			name: "static route", method: http.MethodGet, path: "/api/v1/banner/get",
+			wantStatus: http.StatusOK, wantRoute: "get:",
+		},
+		{
+			name: "trailing slash is ignored", method: http.MethodGet, path: "/api/v1/banner/get/",
+			wantStatus: http.StatusOK, wantRoute: "get:",
+		},
+		{
+			name: "param is matched", method: http.MethodPatch, path: "/api/v1/banner/42",
+			wantStatus: http.StatusOK, wantRoute: "update:42",
+		},
+		{
+			name: "route is chosen by method", method: http.MethodDelete, path: "/api/v1/banner/42",
+			wantStatus: http.StatusOK, wantRoute: "delete:42",
+		},
+		{
+			name: "static segment wins over param", method: http.MethodPost, path: "/api/v1/banner/transfer",
+			wantStatus: http.StatusOK, wantRoute: "transfer:",
+		},
+		{
+			name: "param route is not used when static route matches path", method: http.MethodPatch,
+			path: "/api/v1/banner/transfer", wantStatus: http.StatusMethodNotAllowed, wantAllow: "OPTIONS, POST",
+		},
+		{
+			name: "wrong method of param route", method: http.MethodGet, path: "/api/v1/banner/42",
+			wantStatus: http.StatusMethodNotAllowed, wantAllow: "DELETE, OPTIONS, PATCH",
+		},
+		{
+			name: "options is answered by route", method: http.MethodOptions, path: "/api/v1/banner/42",
+			wantStatus: http.StatusOK, wantRoute: "update:42",
+		},
+		{
+			name: "unknown path", method: http.MethodGet, path: "/api/v1/banner",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "too long path", method: http.MethodPatch, path: "/api/v1/banner/42/tags",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	router := newTestRouter()
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if got := w.Header().Get(headerRoute); got != tt.wantRoute {
+				t.Errorf("route = %q, want %q", got, tt.wantRoute)
+			}
+
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+
+			// own answers of router are wrapped, answers of routes are left to their handlers
+			wantOrigin := ""
+			if tt.wantRoute == "" {
+				wantOrigin = "test"
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, wantOrigin)
+			}
+		})
+	}
+}
+
+func TestRouterWithoutWrap(t *testing.T) {
+	t.Parallel()
+
+	router := NewRouter(nil)
+	router.Handle(http.MethodGet, "/api/v1/banner/get", namedHandler("get"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/banner/get", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	if got := w.Header().Get("Allow"); got != "GET, OPTIONS" {
+		t.Errorf("Allow = %q, want %q", got, "GET, OPTIONS")
+	}
+}
@@ -0,0 +1,25 @@
+package delivery
+
+import (
+	"context"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"net/http"
+)
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying principal verified by auth middleware.
+func WithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// GetPrincipal returns principal of request, or ErrAuthHeaderNotPresented if request
+// has not passed auth middleware.
+func GetPrincipal(r *http.Request) (*models.Principal, error) {
+	principal, ok := r.Context().Value(principalKey{}).(*models.Principal)
+	if !ok || principal == nil {
+		return nil, ErrAuthHeaderNotPresented
+	}
+
+	return principal, nil
+}
@@ -1,30 +0,0 @@
-package repository
-
-import (
-	"context"
-	"fmt"
-	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
-	"github.com/SanExpett/banners-backend/pkg/my_logger"
-	"github.com/jackc/pgx/v5"
-)
-
-func GetLastValSeq(ctx context.Context, tx pgx.Tx, nameTable pgx.Identifier) (uint64, error) {
-	logger, err := my_logger.Get()
-	if err != nil {
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
-	}
-
-	sanitizedNameTable := nameTable.Sanitize()
-	SQLGetLastValSeq := fmt.Sprintf(`SELECT last_value FROM %s;`, sanitizedNameTable)
-	seqRow := tx.QueryRow(ctx, SQLGetLastValSeq)
-
-	var count uint64
-
-	if err := seqRow.Scan(&count); err != nil {
-		logger.Errorf("error in GetLastValSeq: %+v", err)
-
-		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
-	}
-
-	return count, nil
-}
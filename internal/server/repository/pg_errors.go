@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	PgErrCodeUniqueViolation     = "23505"
+	PgErrCodeForeignKeyViolation = "23503"
+)
+
+// pgTransientErrCodes are codes and classes of codes (two first characters) of postgres errors
+// after which the same statement can succeed if it is run again later.
+var pgTransientErrCodes = []string{ //nolint:gochecknoglobals
+	"08",    // connection exception
+	"40",    // transaction rollback: serialization failure, deadlock
+	"53",    // insufficient resources
+	"55P03", // lock not available
+	"57014", // query canceled by statement timeout
+	"57P01", // admin shutdown
+	"57P02", // crash shutdown
+	"57P03", // cannot connect now
+}
+
+// IsPgConstraintErr reports whether err is a postgres error with given code raised by given constraint.
+func IsPgConstraintErr(err error, code string, constraintName string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == code && pgErr.ConstraintName == constraintName
+}
+
+// IsPgErr reports whether err is a postgres error with given code.
+func IsPgErr(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == code
+}
+
+// IsTransientPgErr reports whether err is a failure of connection or of postgres that is expected
+// to pass, so the statement can be retried. Errors of data and of query are not transient.
+func IsTransientPgErr(err error) bool {
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	for _, code := range pgTransientErrCodes {
+		if strings.HasPrefix(pgErr.Code, code) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"testing"
+)
+
+func TestIsPgConstraintErr(t *testing.T) {
+	t.Parallel()
+
+	const constraint = "banner_tag_feature_id_tag_id_uniq"
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "violation of constraint",
+			err:  &pgconn.PgError{Code: PgErrCodeUniqueViolation, ConstraintName: constraint}, //nolint:exhaustruct
+			want: true,
+		},
+		{
+			name: "wrapped violation of constraint",
+			err: fmt.Errorf("insert: %w",
+				&pgconn.PgError{Code: PgErrCodeUniqueViolation, ConstraintName: constraint}), //nolint:exhaustruct
+			want: true,
+		},
+		{
+			name: "violation of other constraint",
+			err:  &pgconn.PgError{Code: PgErrCodeUniqueViolation, ConstraintName: "user_login_key"}, //nolint:exhaustruct
+			want: false,
+		},
+		{
+			name: "other error of constraint",
+			err:  &pgconn.PgError{Code: PgErrCodeForeignKeyViolation, ConstraintName: constraint}, //nolint:exhaustruct
+			want: false,
+		},
+		{
+			name: "not postgres error",
+			err:  errors.New(constraint),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsPgConstraintErr(tt.err, PgErrCodeUniqueViolation, constraint); got != tt.want {
+				t.Errorf("IsPgConstraintErr() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPgErr(t *testing.T) {
+	t.Parallel()
+
+	fkErr := fmt.Errorf("insert: %w", &pgconn.PgError{Code: PgErrCodeForeignKeyViolation}) //nolint:exhaustruct
+
+	if !IsPgErr(fkErr, PgErrCodeForeignKeyViolation) {
+		t.Error("foreign key violation is not recognized")
+	}
+
+	if IsPgErr(fkErr, PgErrCodeUniqueViolation) {
+		t.Error("foreign key violation is taken for unique violation")
+	}
+
+	if IsPgErr(errors.New(PgErrCodeForeignKeyViolation), PgErrCodeForeignKeyViolation) {
+		t.Error("not postgres error is taken for foreign key violation")
+	}
+}
+
+func TestIsTransientPgErr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{name: "deadlock", code: "40P01", want: true},
+		{name: "serialization failure", code: "40001", want: true},
+		{name: "connection failure", code: "08006", want: true},
+		{name: "too many connections", code: "53300", want: true},
+		{name: "admin shutdown", code: "57P01", want: true},
+		{name: "statement timeout", code: "57014", want: true},
+		{name: "lock not available", code: "55P03", want: true},
+		{name: "foreign key violation", code: PgErrCodeForeignKeyViolation, want: false},
+		{name: "syntax error", code: "42601", want: false},
+		{name: "other object in use", code: "55006", want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := fmt.Errorf("delete: %w", &pgconn.PgError{Code: tt.code}) //nolint:exhaustruct
+			if got := IsTransientPgErr(err); got != tt.want {
+				t.Errorf("IsTransientPgErr() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+
+	if IsTransientPgErr(errors.New("40P01")) {
+		t.Error("not postgres error is taken for transient")
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+type pgSubscription struct {
+	onConnect func()
+	onNotify  func(payload string)
+}
+
+// PgListener receives notifications of channels from any instance of service. It holds its own
+// connection, so LISTEN does not leak into connections of pool.
+type PgListener struct {
+	connConfig    *pgx.ConnConfig
+	subscriptions map[string]pgSubscription
+	logger        *zap.SugaredLogger
+}
+
+func NewPgListener(pool *pgxpool.Pool) (*PgListener, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &PgListener{
+		connConfig:    pool.Config().ConnConfig.Copy(),
+		subscriptions: make(map[string]pgSubscription),
+		logger:        logger,
+	}, nil
+}
+
+// Subscribe makes Listen call onNotify with payload of every notification of channel. onConnect
+// is called every time listening of channel starts, because notifications could be missed meanwhile.
+// Subscribe must be called before Listen.
+func (l *PgListener) Subscribe(channel string, onConnect func(), onNotify func(payload string)) {
+	l.subscriptions[channel] = pgSubscription{onConnect: onConnect, onNotify: onNotify}
+}
+
+// Listen receives notifications until ctx is done. If connection drops, it reconnects.
+func (l *PgListener) Listen(ctx context.Context) {
+	delay := minReconnectDelay
+
+	for {
+		startedAt := time.Now()
+
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(startedAt) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+
+		l.logger.Errorf("in PgListener.Listen: reconnect in %s: %+v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay = min(2*delay, maxReconnectDelay)
+	}
+}
+
+func (l *PgListener) listen(ctx context.Context) error {
+	conn, err := pgx.ConnectConfig(ctx, l.connConfig)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	defer conn.Close(context.Background())
+
+	for channel := range l.subscriptions {
+		_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		l.logger.Infof("in PgListener.listen: listening %s", channel)
+	}
+
+	for _, subscription := range l.subscriptions {
+		subscription.onConnect()
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		subscription, ok := l.subscriptions[notification.Channel]
+		if !ok {
+			continue
+		}
+
+		l.logger.Debugf("in PgListener.listen: got notification %s of %s", notification.Payload, notification.Channel)
+		subscription.onNotify(notification.Payload)
+	}
+}
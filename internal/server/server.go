@@ -2,13 +2,21 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	bannerrepo "github.com/SanExpett/banners-backend/internal/banner/repository"
 	bannerusecases "github.com/SanExpett/banners-backend/internal/banner/usecases"
+	featurerepo "github.com/SanExpett/banners-backend/internal/feature/repository"
+	featureusecases "github.com/SanExpett/banners-backend/internal/feature/usecases"
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
 	"github.com/SanExpett/banners-backend/internal/server/delivery/mux"
 	"github.com/SanExpett/banners-backend/internal/server/repository"
+	tagrepo "github.com/SanExpett/banners-backend/internal/tag/repository"
+	tagusecases "github.com/SanExpett/banners-backend/internal/tag/usecases"
 	userrepo "github.com/SanExpett/banners-backend/internal/user/repository"
 	userusecases "github.com/SanExpett/banners-backend/internal/user/usecases"
 	"github.com/SanExpett/banners-backend/pkg/config"
+	"github.com/SanExpett/banners-backend/pkg/jwt"
 	"github.com/SanExpett/banners-backend/pkg/my_logger"
 	"net/http"
 	"strings"
@@ -19,6 +27,9 @@ const (
 	basicTimeout = 10 * time.Second
 )
 
+var ErrUnknownBannerCacheMode = errors.New("unknown banner cache mode, use " +
+	bannerusecases.BannerCacheModeIndex + " or " + bannerusecases.BannerCacheModeTTL)
+
 type Server struct {
 	httpServer *http.Server
 }
@@ -39,28 +50,135 @@ func (s *Server) Run(config *config.Config) error {
 
 	defer logger.Sync()
 
+	signingKey, err := jwt.LoadSigningKey(config.JwtSigningKeyID, config.JwtSigningAlg, config.JwtSigningKey,
+		config.JwtSigningKeyFile)
+	if err != nil {
+		return err
+	}
+
+	verifyKeys, err := jwt.LoadVerifyKeys(config.JwtVerifyKeys)
+	if err != nil {
+		return err
+	}
+
+	keySet, err := jwt.NewKeySet(signingKey, verifyKeys...)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := jwt.NewManager(keySet, config.JwtIssuer, config.JwtAudience, config.JwtTTL, config.JwtLeeway)
+	if err != nil {
+		return err
+	}
+
 	userStorage, err := userrepo.NewUserStorage(pool)
 	if err != nil {
 		return err
 	}
 
-	userService, err := userusecases.NewUserService(userStorage)
+	revokedTokens, err := userusecases.NewRevokedTokens(userStorage)
+	if err != nil {
+		return err
+	}
+
+	if err := revokedTokens.Load(baseCtx); err != nil {
+		return err
+	}
+
+	go revokedTokens.Run(baseCtx)
+
+	userService, err := userusecases.NewUserService(userStorage, tokens, config.JwtRefreshTTL)
+	if err != nil {
+		return err
+	}
+
+	if config.AdminLogin != "" {
+		admin, err := userService.AddFirstAdmin(baseCtx, config.AdminLogin, config.AdminPassword)
+		if err != nil {
+			return err
+		}
+
+		if admin != nil {
+			logger.Infof("first admin %s (id %d) is created", admin.Login, admin.ID)
+		} else {
+			logger.Infof("admin already exists, %s is not created", config.AdminLogin)
+		}
+	}
+
+	bannerStorage, err := bannerrepo.NewBannerStorage(pool, config.BannerVersionsLimit)
+	if err != nil {
+		return err
+	}
+
+	var bannerCache bannerusecases.IBannerCache
+
+	switch config.BannerCacheMode {
+	case bannerusecases.BannerCacheModeTTL:
+		bannerCache = bannerusecases.NewBannerCache(config.BannerCacheTTL)
+	case bannerusecases.BannerCacheModeIndex:
+		bannerIndex, err := bannerusecases.NewBannerIndex(bannerStorage, config.BannerIndexRefresh)
+		if err != nil {
+			return err
+		}
+
+		go bannerIndex.Run(baseCtx)
+
+		bannerCache = bannerIndex
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownBannerCacheMode, config.BannerCacheMode)
+	}
+
+	bannerDeleteWorker, err := bannerusecases.NewBannerDeleteWorker(bannerStorage, bannerCache,
+		config.BannerDeleteBatch, config.BannerDeletePoll)
+	if err != nil {
+		return err
+	}
+
+	go bannerDeleteWorker.Run(baseCtx)
+
+	bannerService, err := bannerusecases.NewBannerService(bannerStorage, bannerCache, bannerDeleteWorker)
+	if err != nil {
+		return err
+	}
+
+	listener, err := repository.NewPgListener(pool)
 	if err != nil {
 		return err
 	}
 
-	bannerStorage, err := bannerrepo.NewBannerStorage(pool)
+	listener.Subscribe(bannerrepo.ChannelBannerChanges, bannerService.HandleBannersChanged, func(string) {
+		bannerService.HandleBannersChanged()
+	})
+	listener.Subscribe(userrepo.ChannelRevokedAccessTokens, func() {
+		revokedTokens.HandleReconnect(baseCtx)
+	}, revokedTokens.HandleRevoked)
+
+	go listener.Listen(baseCtx)
+
+	featureStorage, err := featurerepo.NewFeatureStorage(pool)
 	if err != nil {
 		return err
 	}
 
-	bannerService, err := bannerusecases.NewBannerService(bannerStorage)
+	featureService, err := featureusecases.NewFeatureService(featureStorage)
 	if err != nil {
 		return err
 	}
 
+	tagStorage, err := tagrepo.NewTagStorage(pool)
+	if err != nil {
+		return err
+	}
+
+	tagService, err := tagusecases.NewTagService(tagStorage)
+	if err != nil {
+		return err
+	}
+
+	delivery.SetLegacyErrorStatus(config.LegacyErrorStatus)
+
 	handler, err := mux.NewMux(baseCtx, mux.NewConfigMux(config.AllowOrigin,
-		config.Schema, config.PortServer), userService, bannerService, logger)
+		config.Schema, config.PortServer), userService, bannerService, featureService, tagService, tokens, revokedTokens, logger)
 	if err != nil {
 		return err
 	}
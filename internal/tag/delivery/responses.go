@@ -0,0 +1,32 @@
+package delivery
+
+import "github.com/SanExpett/banners-backend/pkg/models"
+
+const (
+	ResponseSuccessfulUpdateTag = "Тег успешно обновлен"
+	ResponseSuccessfulDeleteTag = "Тег успешно удален"
+)
+
+type TagResponse struct {
+	Status int         `json:"status"`
+	Body   *models.Tag `json:"body"`
+}
+
+func NewTagResponse(status int, body *models.Tag) *TagResponse {
+	return &TagResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type TagListResponse struct {
+	Status int           `json:"status"`
+	Body   []*models.Tag `json:"body"`
+}
+
+func NewTagListResponse(status int, body []*models.Tag) *TagListResponse {
+	return &TagListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
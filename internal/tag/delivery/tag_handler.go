@@ -0,0 +1,231 @@
+package delivery
+
+import (
+	"context"
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	tagusecases "github.com/SanExpett/banners-backend/internal/tag/usecases"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/SanExpett/banners-backend/pkg/utils"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+var _ ITagService = (*tagusecases.TagService)(nil)
+
+type ITagService interface {
+	AddTag(ctx context.Context, r io.Reader) (uint64, error)
+	GetTag(ctx context.Context, tagID uint64) (*models.Tag, error)
+	GetTagsList(ctx context.Context, titlePrefix string, limit uint64, offset uint64) ([]*models.Tag, error)
+	UpdateTag(ctx context.Context, tagID uint64, r io.Reader) error
+	DeleteTag(ctx context.Context, tagID uint64) error
+}
+
+type TagHandler struct {
+	service ITagService
+	logger  *zap.SugaredLogger
+}
+
+func NewTagHandler(tagService ITagService) (*TagHandler, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TagHandler{
+		service: tagService,
+		logger:  logger,
+	}, nil
+}
+
+// AddTagHandler godoc
+//
+//	@Summary    add tag
+//	@Description  add tag by data
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags Tag
+//
+//	@Accept      json
+//	@Produce    json
+//	@Param      tag  body models.PreTag true  "tag data for adding"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} delivery.ResponseID
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /tag/add [post]
+func (t *TagHandler) AddTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tagID, err := t.service.AddTag(ctx, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, t.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, t.logger, delivery.NewResponseID(tagID))
+	t.logger.Infof("in AddTagHandler: added tag id= %+v", tagID)
+}
+
+// GetTagHandler godoc
+//
+//	@Summary    get tag
+//	@Description  get tag by id with count of banners which have it
+//	@Tags Tag
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "tag id"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} TagResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /tag/get [get]
+func (t *TagHandler) GetTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tagID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, t.logger, err)
+
+		return
+	}
+
+	tag, err := t.service.GetTag(ctx, tagID)
+	if err != nil {
+		delivery.HandleErr(w, r, t.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, t.logger, NewTagResponse(delivery.StatusResponseSuccessful, tag))
+	t.logger.Infof("in GetTagHandler: get tag: %+v", tag)
+}
+
+// GetTagsListHandler godoc
+//
+//	@Summary    get tags list
+//	@Description  get tags list ordered by id with count of banners of every tag.
+//	@Description  If title_prefix is set, only tags which title starts with it are returned.
+//	@Tags Tag
+//	@Accept      json
+//	@Produce    json
+//	@Param      title_prefix  query string false  "prefix of tag title"
+//	@Param      limit  query uint64 false  "limit of tags"
+//	@Param      offset  query uint64 false  "offset of tags"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} TagListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /tag/get_list [get]
+func (t *TagHandler) GetTagsListHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limit, err := utils.ParseUint64FromRequest(r, "limit")
+	if err != nil {
+		limit = 10
+	}
+
+	offset, err := utils.ParseUint64FromRequest(r, "offset")
+	if err != nil {
+		offset = 0
+	}
+
+	titlePrefix := r.URL.Query().Get("title_prefix")
+
+	tags, err := t.service.GetTagsList(ctx, titlePrefix, limit, offset)
+	if err != nil {
+		delivery.HandleErr(w, r, t.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, t.logger, NewTagListResponse(delivery.StatusResponseSuccessful, tags))
+	t.logger.Infof("in GetTagsListHandler: get tags list: %+v", tags)
+}
+
+// UpdateTagHandler godoc
+//
+//	@Summary    update tag
+//	@Description  change title and description of tag
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags Tag
+//
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "tag id"
+//	@Param      tag  body models.PreTag true  "tag data for updating"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /tag/update [patch]
+func (t *TagHandler) UpdateTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tagID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, t.logger, err)
+
+		return
+	}
+
+	err = t.service.UpdateTag(ctx, tagID, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, t.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, t.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulUpdateTag))
+	t.logger.Infof("in UpdateTagHandler: updated tag id=%d", tagID)
+}
+
+// DeleteTagHandler godoc
+//
+//	@Summary    delete tag
+//	@Description  delete tag. Tag which banners have is not deleted.
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description StatusErrConflict        = 409 (body.details has tag_id and banners_count)
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags Tag
+//	@Accept      json
+//	@Produce    json
+//	@Param      id  query uint64 true  "tag id"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /tag/delete [delete]
+func (t *TagHandler) DeleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tagID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, t.logger, err)
+
+		return
+	}
+
+	err = t.service.DeleteTag(ctx, tagID)
+	if err != nil {
+		delivery.HandleErr(w, r, t.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, t.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteTag))
+	t.logger.Infof("in DeleteTagHandler: deleted tag id=%d", tagID)
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"strings"
+)
+
+var (
+	ErrTagNotFound = myerrors.NewNotFoundError("tag_not_found", myerrors.Message{
+		RU: "Этот тег не найден",
+		EN: "Tag is not found",
+	})
+
+	MessageErrTagInUse = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Тег есть у баннеров, сначала уберите его из них",
+		EN: "Tag is used by banners, remove it from them first",
+	}
+
+	likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`) //nolint:gochecknoglobals
+)
+
+type TagStorage struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+func NewTagStorage(pool *pgxpool.Pool) (*TagStorage, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &TagStorage{
+		pool:   pool,
+		logger: logger,
+	}, nil
+}
+
+func (t *TagStorage) AddTag(ctx context.Context, preTag *models.PreTag) (uint64, error) {
+	SQLCreateTag := `INSERT INTO public."tag" (title, description) VALUES ($1, $2) RETURNING id`
+
+	var tagID uint64
+
+	tagRow := t.pool.QueryRow(ctx, SQLCreateTag, preTag.Title, preTag.Description)
+	if err := tagRow.Scan(&tagID); err != nil {
+		t.logger.Errorf("in AddTag: preTag=%+v err=%+v", preTag, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return tagID, nil
+}
+
+func (t *TagStorage) GetTag(ctx context.Context, tagID uint64) (*models.Tag, error) {
+	SQLSelectTag := `SELECT t.id, t.title, t.description,
+			(SELECT COUNT(*) FROM public."banner_tag" bt WHERE bt.tag_id = t.id), t.created_at
+		FROM public."tag" t
+		WHERE t.id=$1`
+
+	tag := &models.Tag{} //nolint:exhaustruct
+
+	tagRow := t.pool.QueryRow(ctx, SQLSelectTag, tagID)
+	if err := tagRow.Scan(&tag.ID, &tag.Title, &tag.Description, &tag.BannersCount, &tag.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrTagNotFound)
+		}
+
+		t.logger.Errorf("error with tagID=%d: %+v", tagID, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return tag, nil
+}
+
+// GetTagsList returns tags which title starts with titlePrefix, empty prefix matches every tag.
+func (t *TagStorage) GetTagsList(ctx context.Context, titlePrefix string, limit uint64,
+	offset uint64) ([]*models.Tag, error) {
+	SQLSelectTags := `SELECT t.id, t.title, t.description,
+			(SELECT COUNT(*) FROM public."banner_tag" bt WHERE bt.tag_id = t.id), t.created_at
+		FROM public."tag" t
+		WHERE t.title LIKE $1 || '%'
+		ORDER BY t.id
+		LIMIT $2 OFFSET $3`
+
+	tagsRows, err := t.pool.Query(ctx, SQLSelectTags, likeEscaper.Replace(titlePrefix), limit, offset)
+	if err != nil {
+		t.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curTag := new(models.Tag)
+
+	var slTags []*models.Tag
+
+	_, err = pgx.ForEachRow(tagsRows, []any{
+		&curTag.ID, &curTag.Title, &curTag.Description, &curTag.BannersCount, &curTag.CreatedAt,
+	}, func() error {
+		slTags = append(slTags, &models.Tag{
+			ID:           curTag.ID,
+			Title:        curTag.Title,
+			Description:  curTag.Description,
+			BannersCount: curTag.BannersCount,
+			CreatedAt:    curTag.CreatedAt,
+		})
+
+		return nil
+	})
+	if err != nil {
+		t.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return slTags, nil
+}
+
+func (t *TagStorage) UpdateTag(ctx context.Context, tagID uint64, preTag *models.PreTag) error {
+	SQLUpdateTag := `UPDATE public."tag" SET title = $1, description = $2 WHERE id = $3`
+
+	result, err := t.pool.Exec(ctx, SQLUpdateTag, preTag.Title, preTag.Description, tagID)
+	if err != nil {
+		t.logger.Errorf("in UpdateTag: preTag=%+v err=%+v", preTag, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf(myerrors.ErrTemplate, ErrTagNotFound)
+	}
+
+	return nil
+}
+
+// lockTag locks tag, so it can not be given to banner until transaction ends.
+func (t *TagStorage) lockTag(ctx context.Context, tx pgx.Tx, tagID uint64) error {
+	SQLLockTag := `SELECT id FROM public."tag" WHERE id=$1 FOR UPDATE`
+
+	var id uint64
+
+	tagRow := tx.QueryRow(ctx, SQLLockTag, tagID)
+	if err := tagRow.Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf(myerrors.ErrTemplate, ErrTagNotFound)
+		}
+
+		t.logger.Errorf("error with tagID=%d: %+v", tagID, err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (t *TagStorage) countBanners(ctx context.Context, tx pgx.Tx, tagID uint64) (uint64, error) {
+	SQLCountBanners := `SELECT COUNT(*) FROM public."banner_tag" WHERE tag_id=$1`
+
+	var bannersCount uint64
+
+	countRow := tx.QueryRow(ctx, SQLCountBanners, tagID)
+	if err := countRow.Scan(&bannersCount); err != nil {
+		t.logger.Errorf("error with tagID=%d: %+v", tagID, err)
+
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bannersCount, nil
+}
+
+// DeleteTag deletes tag if no banner has it, otherwise it is refused with conflict error.
+func (t *TagStorage) DeleteTag(ctx context.Context, tagID uint64) error {
+	SQLDeleteTag := `DELETE FROM public."tag" WHERE id=$1`
+
+	err := pgx.BeginFunc(ctx, t.pool, func(tx pgx.Tx) error {
+		err := t.lockTag(ctx, tx, tagID)
+		if err != nil {
+			return err
+		}
+
+		bannersCount, err := t.countBanners(ctx, tx, tagID)
+		if err != nil {
+			return err
+		}
+
+		if bannersCount != 0 {
+			return myerrors.NewConflictError(models.TagInUse{TagID: tagID, BannersCount: bannersCount},
+				"tag_in_use", MessageErrTagInUse)
+		}
+
+		_, err = tx.Exec(ctx, SQLDeleteTag, tagID)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.logger.Errorln(err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
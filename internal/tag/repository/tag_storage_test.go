@@ -0,0 +1,25 @@
+package repository
+
+import "testing"
+
+func TestLikeEscaper(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{prefix: "sale", want: "sale"},
+		{prefix: "", want: ""},
+		{prefix: "50%", want: `50\%`},
+		{prefix: "new_year", want: `new\_year`},
+		{prefix: `a\b`, want: `a\\b`},
+		{prefix: `%_\`, want: `\%\_\\`},
+	}
+
+	for _, tt := range tests {
+		if got := likeEscaper.Replace(tt.prefix); got != tt.want {
+			t.Errorf("likeEscaper.Replace(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
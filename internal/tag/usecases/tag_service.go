@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	tagrepo "github.com/SanExpett/banners-backend/internal/tag/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"go.uber.org/zap"
+	"io"
+)
+
+var _ ITagStorage = (*tagrepo.TagStorage)(nil)
+
+type ITagStorage interface {
+	AddTag(ctx context.Context, preTag *models.PreTag) (uint64, error)
+	GetTag(ctx context.Context, tagID uint64) (*models.Tag, error)
+	GetTagsList(ctx context.Context, titlePrefix string, limit uint64, offset uint64) ([]*models.Tag, error)
+	UpdateTag(ctx context.Context, tagID uint64, preTag *models.PreTag) error
+	DeleteTag(ctx context.Context, tagID uint64) error
+}
+
+type TagService struct {
+	storage ITagStorage
+	logger  *zap.SugaredLogger
+}
+
+func NewTagService(tagStorage ITagStorage) (*TagService, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &TagService{storage: tagStorage, logger: logger}, nil
+}
+
+func (t *TagService) AddTag(ctx context.Context, r io.Reader) (uint64, error) {
+	preTag, err := ValidatePreTag(r)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	tagID, err := t.storage.AddTag(ctx, preTag)
+	if err != nil {
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return tagID, nil
+}
+
+func (t *TagService) GetTag(ctx context.Context, tagID uint64) (*models.Tag, error) {
+	tag, err := t.storage.GetTag(ctx, tagID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return tag, nil
+}
+
+func (t *TagService) GetTagsList(ctx context.Context, titlePrefix string, limit uint64,
+	offset uint64) ([]*models.Tag, error) {
+	tags, err := t.storage.GetTagsList(ctx, titlePrefix, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return tags, nil
+}
+
+func (t *TagService) UpdateTag(ctx context.Context, tagID uint64, r io.Reader) error {
+	preTag, err := ValidatePreTag(r)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = t.storage.UpdateTag(ctx, tagID, preTag)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+func (t *TagService) DeleteTag(ctx context.Context, tagID uint64) error {
+	err := t.storage.DeleteTag(ctx, tagID)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
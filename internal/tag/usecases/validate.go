@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/asaskevich/govalidator"
+	"io"
+)
+
+var (
+	ErrDecodePreTag = myerrors.NewError("bad_tag_json", myerrors.Message{
+		RU: "Некорректный json тега",
+		EN: "Invalid json of tag",
+	})
+	ErrWrongTag = myerrors.NewError("wrong_tag", myerrors.Message{
+		RU: "Название тега должно быть длиной от 1 до 150 символов, а описание не длиннее 1000 символов",
+		EN: "Title of tag must be from 1 to 150 characters long and description not longer than 1000 characters",
+	})
+)
+
+func ValidatePreTag(r io.Reader) (*models.PreTag, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	decoder := json.NewDecoder(r)
+
+	preTag := new(models.PreTag)
+	if err := decoder.Decode(preTag); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodePreTag)
+	}
+
+	preTag.Trim()
+
+	_, err = govalidator.ValidateStruct(preTag)
+	if err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongTag)
+	}
+
+	return preTag, nil
+}
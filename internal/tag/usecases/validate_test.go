@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if _, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestValidatePreTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		body            string
+		wantTitle       string
+		wantDescription string
+		wantErr         error
+	}{
+		{name: "title only", body: `{"title": "sale"}`, wantTitle: "sale"},
+		{
+			name:      "fields are trimmed",
+			body:      `{"title": " sale ", "description": " for sale banners\n"}`,
+			wantTitle: "sale", wantDescription: "for sale banners",
+		},
+		{
+			name:      "longest fields in runes",
+			body:      `{"title": "` + strings.Repeat("т", 150) + `", "description": "` + strings.Repeat("о", 1000) + `"}`,
+			wantTitle: strings.Repeat("т", 150), wantDescription: strings.Repeat("о", 1000),
+		},
+		{name: "too long title", body: `{"title": "` + strings.Repeat("т", 151) + `"}`, wantErr: ErrWrongTag},
+		{
+			name:    "too long description",
+			body:    `{"title": "sale", "description": "` + strings.Repeat("о", 1001) + `"}`,
+			wantErr: ErrWrongTag,
+		},
+		{name: "empty title", body: `{"title": " ", "description": "d"}`, wantErr: ErrWrongTag},
+		{name: "bad json", body: `{"title": `, wantErr: ErrDecodePreTag},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			preTag, err := ValidatePreTag(strings.NewReader(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (preTag.Title != tt.wantTitle || preTag.Description != tt.wantDescription) {
+				t.Errorf("tag = %+v, want title %q and description %q", preTag, tt.wantTitle, tt.wantDescription)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package delivery
+
+import "github.com/SanExpett/banners-backend/pkg/models"
+
+const (
+	ResponseSuccessfulDeleteRoleBinding = "Successful delete of role binding"
+)
+
+type TokensResponse struct {
+	Status int            `json:"status"`
+	Body   *models.Tokens `json:"body"`
+}
+
+func NewTokensResponse(status int, body *models.Tokens) *TokensResponse {
+	return &TokensResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type UserResponse struct {
+	Status int                         `json:"status"`
+	Body   *models.UserWithoutPassword `json:"body"`
+}
+
+func NewUserResponse(status int, body *models.UserWithoutPassword) *UserResponse {
+	return &UserResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type RoleListResponse struct {
+	Status int            `json:"status"`
+	Body   []*models.Role `json:"body"`
+}
+
+func NewRoleListResponse(status int, body []*models.Role) *RoleListResponse {
+	return &RoleListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type RoleBindingResponse struct {
+	Status int                 `json:"status"`
+	Body   *models.RoleBinding `json:"body"`
+}
+
+func NewRoleBindingResponse(status int, body *models.RoleBinding) *RoleBindingResponse {
+	return &RoleBindingResponse{
+		Status: status,
+		Body:   body,
+	}
+}
+
+type RoleBindingListResponse struct {
+	Status int                   `json:"status"`
+	Body   []*models.RoleBinding `json:"body"`
+}
+
+func NewRoleBindingListResponse(status int, body []*models.RoleBinding) *RoleBindingListResponse {
+	return &RoleBindingListResponse{
+		Status: status,
+		Body:   body,
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/SanExpett/banners-backend/pkg/utils"
 	"io"
 	"net/http"
-	"time"
 
 	"github.com/SanExpett/banners-backend/internal/server/delivery"
 	userusecases "github.com/SanExpett/banners-backend/internal/user/usecases"
@@ -16,15 +15,12 @@ import (
 )
 
 const (
-	timeTokenLife = 24 * time.Hour
+	jwksCacheControl = "public, max-age=300"
 
-	StatusUnauthorized = 401
-
-	ResponseSuccessfulSignUp = "Successful sign up"
-	ResponseSuccessfulSignIn = "Successful sign in"
-	ResponseSuccessfulLogOut = "Successful log out"
-
-	ErrUnauthorized = "Вы не авторизованны"
+	ResponseSuccessfulSignUp           = "Successful sign up"
+	ResponseSuccessfulSignIn           = "Successful sign in"
+	ResponseSuccessfulLogOut           = "Successful log out"
+	ResponseSuccessfulLogOutEverywhere = "Successful log out from all sessions"
 )
 
 var _ IUserService = (*userusecases.UserService)(nil)
@@ -32,14 +28,24 @@ var _ IUserService = (*userusecases.UserService)(nil)
 type IUserService interface {
 	AddUser(ctx context.Context, r io.Reader) (*models.User, error)
 	GetUser(ctx context.Context, login string, password string) (*models.UserWithoutPassword, error)
+	IssueTokens(ctx context.Context, user *models.UserWithoutPassword) (*models.Tokens, error)
+	RefreshTokens(ctx context.Context, r io.Reader) (*models.Tokens, error)
+	LogOut(ctx context.Context, userID uint64, sessionID string) error
+	LogOutEverywhere(ctx context.Context, userID uint64) error
+	SetUserAdmin(ctx context.Context, actorID uint64, userID uint64, isAdmin bool) (*models.UserWithoutPassword, error)
+	GetRoles() []*models.Role
+	AddRoleBinding(ctx context.Context, r io.Reader) (*models.RoleBinding, error)
+	GetRoleBindings(ctx context.Context, userID uint64) ([]*models.RoleBinding, error)
+	DeleteRoleBinding(ctx context.Context, bindingID uint64) (*models.RoleBinding, error)
 }
 
 type UserHandler struct {
 	service IUserService
+	tokens  *jwt.Manager
 	logger  *zap.SugaredLogger
 }
 
-func NewUserHandler(userService IUserService) (*UserHandler, error) {
+func NewUserHandler(userService IUserService, tokens *jwt.Manager) (*UserHandler, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, err
@@ -47,6 +53,7 @@ func NewUserHandler(userService IUserService) (*UserHandler, error) {
 
 	return &UserHandler{
 		service: userService,
+		tokens:  tokens,
 		logger:  logger,
 	}, nil
 }
@@ -64,48 +71,33 @@ func NewUserHandler(userService IUserService) (*UserHandler, error) {
 //	@Accept      json
 //	@Produce    json
 //	@Param      preUser  body models.PreUser true  "user data for signup"
-//	@Success    200  {object} delivery.Response
+//	@Success    200  {object} TokensResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
 //	@Router      /signup [post]
 func (u *UserHandler) SignUpHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
-
-		return
-	}
-
 	ctx := r.Context()
 
 	user, err := u.service.AddUser(ctx, r.Body)
 	if err != nil {
-		delivery.HandleErr(w, u.logger, err)
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	expire := time.Now().Add(timeTokenLife)
-
-	jwtStr, err := jwt.GenerateJwtToken(&jwt.UserJwtPayload{
-		UserID:  user.ID,
+	tokens, err := u.service.IssueTokens(ctx, &models.UserWithoutPassword{
+		ID:      user.ID,
 		Login:   user.Login,
 		IsAdmin: user.IsAdmin,
-		Expire:  expire.Unix(),
-	},
-		jwt.Secret,
-		u.logger,
-	)
+	})
 	if err != nil {
-		delivery.SendErrResponse(w, u.logger,
-			delivery.NewErrResponse(delivery.StatusErrInternalServer, delivery.ErrInternalServer))
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	w.Header().Set("Authorization", "Bearer "+jwtStr)
-
-	delivery.SendOkResponse(w, u.logger, delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulSignUp))
+	u.sendTokens(w, tokens)
 	u.logger.Infof("in SignUpHandler: added user: %+v", user)
 }
 
@@ -117,18 +109,12 @@ func (u *UserHandler) SignUpHandler(w http.ResponseWriter, r *http.Request) {
 //	@Produce    json
 //	@Param      login  query string true  "user login for signin"
 //	@Param      password  query string true  "user password for signin"
-//	@Success    200  {object} delivery.Response
+//	@Success    200  {object} TokensResponse
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
 //	@Router      /signin [get]
 func (u *UserHandler) SignInHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
-
-		return
-	}
-
 	ctx := r.Context()
 
 	login := utils.ParseStringFromRequest(r, "login")
@@ -136,56 +122,83 @@ func (u *UserHandler) SignInHandler(w http.ResponseWriter, r *http.Request) {
 
 	user, err := u.service.GetUser(ctx, login, password)
 	if err != nil {
-		delivery.HandleErr(w, u.logger, err)
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	expire := time.Now().Add(timeTokenLife)
+	tokens, err := u.service.IssueTokens(ctx, user)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
 
-	jwtStr, err := jwt.GenerateJwtToken(&jwt.UserJwtPayload{
-		UserID:  user.ID,
-		Login:   user.Login,
-		IsAdmin: user.IsAdmin,
-		Expire:  expire.Unix(),
-	},
-		jwt.Secret,
-		u.logger,
-	)
+	u.sendTokens(w, tokens)
+	u.logger.Infof("in SignInHandler: signin user: %+v", user)
+}
+
+// RefreshHandler godoc
+//
+//	@Summary    refresh
+//	@Description  exchange refresh token for new access and refresh tokens of the same session.
+//	@Description  Refresh token can be used only once, reuse of it revokes the whole session
+//	@Tags auth
+//	@Accept      json
+//	@Produce    json
+//	@Param      refreshToken  body models.RefreshTokenRequest true  "refresh token"
+//	@Success    200  {object} TokensResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /refresh [post]
+func (u *UserHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tokens, err := u.service.RefreshTokens(ctx, r.Body)
 	if err != nil {
-		delivery.SendErrResponse(w, u.logger,
-			delivery.NewErrResponse(delivery.StatusErrInternalServer, delivery.ErrInternalServer))
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	w.Header().Set("Authorization", "Bearer "+jwtStr)
+	u.sendTokens(w, tokens)
+	u.logger.Infof("in RefreshHandler: refreshed tokens")
+}
 
-	delivery.SendOkResponse(w, u.logger, delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulSignIn))
-	u.logger.Infof("in SignInHandler: signin user: %+v", user)
+// sendTokens sends tokens in body, access token is also put into Authorization header as before.
+func (u *UserHandler) sendTokens(w http.ResponseWriter, tokens *models.Tokens) {
+	w.Header().Set("Authorization", tokens.TokenType+" "+tokens.AccessToken)
+
+	delivery.SendOkResponse(w, u.logger, NewTokensResponse(delivery.StatusResponseSuccessful, tokens))
 }
 
 // LogOutHandler godoc
 //
 //	@Summary    logout
-//	@Description  logout in app
+//	@Description  logout in app: refresh tokens of current session are revoked and its access tokens
+//	@Description  are rejected until they expire
 //	@Tags auth
 //	@Produce    json
+//	@Param      token  header string true  "user token"
 //	@Success    200  {object} delivery.Response
 //	@Failure    405  {string} string
 //	@Failure    500  {string} string
-//	@Failure    222  {object} delivery.ErrorResponse "Error"
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
 //	@Router      /logout [post]
 func (u *UserHandler) LogOutHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, `Method not allowed`, http.StatusMethodNotAllowed)
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
 
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		delivery.SendErrResponse(w, u.logger, delivery.NewErrResponse(StatusUnauthorized, ErrUnauthorized))
+	err = u.service.LogOut(ctx, principal.UserID, principal.SessionID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
 
 		return
 	}
@@ -193,5 +206,229 @@ func (u *UserHandler) LogOutHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Authorization", "")
 
 	delivery.SendOkResponse(w, u.logger, delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulLogOut))
-	u.logger.Infof("in LogOutHandler: logout user and cleared Authorization header")
+	u.logger.Infof("in LogOutHandler: logout user %d from session %s", principal.UserID, principal.SessionID)
+}
+
+// LogOutEverywhereHandler godoc
+//
+//	@Summary    logout from all sessions
+//	@Description  logout on every device: all refresh tokens of user are revoked and its access tokens
+//	@Description  are rejected until they expire
+//	@Tags auth
+//	@Produce    json
+//	@Param      token  header string true  "user token"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /logout_all [post]
+func (u *UserHandler) LogOutEverywhereHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	err = u.service.LogOutEverywhere(ctx, principal.UserID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	w.Header().Set("Authorization", "")
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulLogOutEverywhere))
+	u.logger.Infof("in LogOutEverywhereHandler: logout user %d from all sessions", principal.UserID)
+}
+
+// SetAdminHandler godoc
+//
+//	@Summary    grant or revoke admin rights
+//	@Description  grant admin rights to user or revoke them. User gets new rights in tokens after refresh,
+//	@Description  when rights are revoked all sessions of user are ended. Admin can not revoke own rights
+//	@Tags User
+//	@Produce    json
+//	@Param      id  query uint64 true  "user id"
+//	@Param      is_admin  query bool true  "true to grant admin rights, false to revoke them"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} UserResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /user/set_admin [post]
+func (u *UserHandler) SetAdminHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, err := delivery.GetPrincipal(r)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	userID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	isAdmin, err := utils.ParseRequiredBoolFromRequest(r, "is_admin")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	user, err := u.service.SetUserAdmin(ctx, principal.UserID, userID, isAdmin)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewUserResponse(delivery.StatusResponseSuccessful, user))
+	u.logger.Infof("in SetAdminHandler: admin %d set is_admin=%t for user %d", principal.UserID, isAdmin, userID)
+}
+
+// GetRolesListHandler godoc
+//
+//	@Summary    get roles list
+//	@Description  get roles, which can be bound to users, with their permissions on banners
+//	@Tags Role
+//	@Produce    json
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} RoleListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /role/get_list [get]
+func (u *UserHandler) GetRolesListHandler(w http.ResponseWriter, r *http.Request) {
+	roles := u.service.GetRoles()
+
+	delivery.SendOkResponse(w, u.logger, NewRoleListResponse(delivery.StatusResponseSuccessful, roles))
+	u.logger.Infof("in GetRolesListHandler: get roles: %+v", roles)
+}
+
+// AddRoleBindingHandler godoc
+//
+//	@Summary    add role binding
+//	@Description  give role to user on banners of feature, or of all features if feature_id is 0.
+//	@Description  User gets permissions of role in tokens after refresh or new sign in
+//	@Description Error.status can be:
+//	@Description StatusErrBadRequest      = 400
+//	@Description StatusErrNotFound        = 404 (user or feature does not exist)
+//	@Description StatusErrConflict        = 409 (user already has this role on this feature)
+//	@Description  StatusErrInternalServer  = 500
+//	@Tags Role
+//	@Accept      json
+//	@Produce    json
+//	@Param      binding  body models.PreRoleBinding true  "role binding data for adding"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} RoleBindingResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /role_binding/add [post]
+func (u *UserHandler) AddRoleBindingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	binding, err := u.service.AddRoleBinding(ctx, r.Body)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewRoleBindingResponse(delivery.StatusResponseSuccessful, binding))
+	u.logger.Infof("in AddRoleBindingHandler: added role binding: %+v", binding)
+}
+
+// GetRoleBindingsListHandler godoc
+//
+//	@Summary    get role bindings list
+//	@Description  get role bindings of user ordered by id
+//	@Tags Role
+//	@Produce    json
+//	@Param      user_id  query uint64 true  "user id"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} RoleBindingListResponse
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /role_binding/get_list [get]
+func (u *UserHandler) GetRoleBindingsListHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, err := utils.ParseUint64FromRequest(r, "user_id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	bindings, err := u.service.GetRoleBindings(ctx, userID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger, NewRoleBindingListResponse(delivery.StatusResponseSuccessful, bindings))
+	u.logger.Infof("in GetRoleBindingsListHandler: get role bindings: %+v", bindings)
+}
+
+// DeleteRoleBindingHandler godoc
+//
+//	@Summary    delete role binding
+//	@Description  take role from user, all sessions of user are ended
+//	@Tags Role
+//	@Produce    json
+//	@Param      id  query uint64 true  "role binding id"
+//	@Param      token  header string true  "admin token"
+//	@Success    200  {object} delivery.Response
+//	@Failure    405  {string} string
+//	@Failure    500  {string} string
+//	@Failure    default  {object} delivery.ErrorResponse "Error, HTTP status is equal to status in body"
+//	@Router      /role_binding/delete [delete]
+func (u *UserHandler) DeleteRoleBindingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bindingID, err := utils.ParseUint64FromRequest(r, "id")
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	binding, err := u.service.DeleteRoleBinding(ctx, bindingID)
+	if err != nil {
+		delivery.HandleErr(w, r, u.logger, err)
+
+		return
+	}
+
+	delivery.SendOkResponse(w, u.logger,
+		delivery.NewResponse(delivery.StatusResponseSuccessful, ResponseSuccessfulDeleteRoleBinding))
+	u.logger.Infof("in DeleteRoleBindingHandler: deleted role binding: %+v", binding)
+}
+
+// JwksHandler godoc
+//
+//	@Summary    jwks
+//	@Description  public keys which verify user tokens, in JWKS format. HMAC keys are never published
+//	@Tags auth
+//	@Produce    json
+//	@Success    200  {object} jwt.JWKS
+//	@Failure    405  {string} string
+//	@Router      /.well-known/jwks.json [get]
+func (u *UserHandler) JwksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", jwksCacheControl)
+
+	delivery.SendOkResponse(w, u.logger, u.tokens.JWKS())
 }
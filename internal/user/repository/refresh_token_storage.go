@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// ChannelRevokedAccessTokens is notified with every access token revoked before it expired.
+const ChannelRevokedAccessTokens = "revoked_access_tokens"
+
+var (
+	ErrInvalidRefreshToken = myerrors.NewUnauthorizedError("invalid_refresh_token", myerrors.Message{
+		RU: "Некорректный refresh токен",
+		EN: "Invalid refresh token",
+	})
+	ErrRefreshTokenExpired = myerrors.NewUnauthorizedError("refresh_token_expired", myerrors.Message{
+		RU: "Срок действия refresh токена истек, войдите заново",
+		EN: "Refresh token has expired, sign in again",
+	})
+	ErrRefreshTokenReused = myerrors.NewUnauthorizedError("refresh_token_reused", myerrors.Message{
+		RU: "Refresh токен уже был использован, все токены этого входа отозваны, войдите заново",
+		EN: "Refresh token has already been used, all tokens of this sign in are revoked, sign in again",
+	})
+)
+
+// AddRefreshToken saves token unless its family has been revoked meanwhile, then ErrRefreshTokenReused
+// is returned. Expired tokens of user are deleted on the way.
+func (u *UserStorage) AddRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	SQLDeleteExpiredRefreshTokens := `DELETE FROM public."refresh_token" WHERE user_id = $1 AND expires_at <= NOW();`
+
+	SQLAddRefreshToken :=
+		`INSERT INTO public."refresh_token" (user_id, family_id, token_hash, access_jti, access_expires_at, expires_at)
+		 SELECT $1::BIGINT, $2::TEXT, $3::TEXT, $4::TEXT, $5::TIMESTAMPTZ, $6::TIMESTAMPTZ
+		 WHERE NOT EXISTS (SELECT 1 FROM public."refresh_token" WHERE family_id = $2 AND revoked_at IS NOT NULL);`
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, SQLDeleteExpiredRefreshTokens, token.UserID)
+		if err != nil {
+			u.logger.Errorf("in AddRefreshToken: userID=%d err=%+v", token.UserID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		result, err := tx.Exec(ctx, SQLAddRefreshToken, token.UserID, token.FamilyID, token.TokenHash,
+			token.AccessJti, token.AccessExpiresAt, token.ExpiresAt)
+		if err != nil {
+			u.logger.Errorf("in AddRefreshToken: userID=%d familyID=%s err=%+v", token.UserID, token.FamilyID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// UseRefreshToken marks token with tokenHash as used and returns it with its user. Token can be used
+// only once: if it is presented again, it was stolen, so the whole family is revoked and
+// ErrRefreshTokenReused is returned.
+func (u *UserStorage) UseRefreshToken(ctx context.Context,
+	tokenHash string) (*models.RefreshToken, *models.UserWithoutPassword, error) {
+	var (
+		token    *models.RefreshToken
+		user     *models.UserWithoutPassword
+		reuseErr error
+	)
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		var err error
+
+		token, user, err = u.useRefreshToken(ctx, tx, tokenHash)
+		// family of reused token is revoked in tx, so tx is committed and the error is returned after it
+		if errors.Is(err, ErrRefreshTokenReused) {
+			reuseErr = err
+
+			return nil
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	if reuseErr != nil {
+		return nil, nil, reuseErr
+	}
+
+	return token, user, nil
+}
+
+// useRefreshToken is UseRefreshToken in tx, so reuse of token is detected and its family is revoked
+// together and concurrent refresh with the same token can not slip between them.
+func (u *UserStorage) useRefreshToken(ctx context.Context, tx pgx.Tx,
+	tokenHash string) (*models.RefreshToken, *models.UserWithoutPassword, error) {
+	SQLUseRefreshToken :=
+		`UPDATE public."refresh_token" AS rt SET used_at = NOW()
+		 FROM public."user" AS u
+		 WHERE u.id = rt.user_id AND rt.token_hash = $1
+		   AND rt.used_at IS NULL AND rt.revoked_at IS NULL AND rt.expires_at > NOW()
+		 RETURNING rt.id, rt.user_id, rt.family_id, u.login, COALESCE(u.is_admin, FALSE);`
+
+	token := &models.RefreshToken{TokenHash: tokenHash} //nolint:exhaustruct
+	user := &models.UserWithoutPassword{}               //nolint:exhaustruct
+
+	tokenRow := tx.QueryRow(ctx, SQLUseRefreshToken, tokenHash)
+	if err := tokenRow.Scan(&token.ID, &token.UserID, &token.FamilyID, &user.Login, &user.IsAdmin); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, u.explainNotUsedRefreshToken(ctx, tx, tokenHash)
+		}
+
+		u.logger.Errorf("in useRefreshToken: err=%+v", err)
+
+		return nil, nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	user.ID = token.UserID
+
+	return token, user, nil
+}
+
+// explainNotUsedRefreshToken tells why token with tokenHash could not be used and revokes
+// its family in tx if token is reused.
+func (u *UserStorage) explainNotUsedRefreshToken(ctx context.Context, tx pgx.Tx, tokenHash string) error {
+	SQLGetRefreshTokenState :=
+		`SELECT user_id, family_id, used_at IS NOT NULL, revoked_at IS NOT NULL
+		 FROM public."refresh_token" WHERE token_hash = $1 FOR UPDATE;`
+
+	var (
+		userID   uint64
+		familyID string
+		used     bool
+		revoked  bool
+	)
+
+	stateRow := tx.QueryRow(ctx, SQLGetRefreshTokenState, tokenHash)
+	if err := stateRow.Scan(&userID, &familyID, &used, &revoked); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf(myerrors.ErrTemplate, ErrInvalidRefreshToken)
+		}
+
+		u.logger.Errorf("in explainNotUsedRefreshToken: err=%+v", err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	switch {
+	case revoked:
+		return fmt.Errorf(myerrors.ErrTemplate, ErrInvalidRefreshToken)
+	case used:
+		u.logger.Warnf("in explainNotUsedRefreshToken: reuse of refresh token, userID=%d familyID=%s",
+			userID, familyID)
+
+		if err := u.revokeRefreshTokens(ctx, tx, userID, familyID); err != nil {
+			return err
+		}
+
+		return fmt.Errorf(myerrors.ErrTemplate, ErrRefreshTokenReused)
+	default:
+		return fmt.Errorf(myerrors.ErrTemplate, ErrRefreshTokenExpired)
+	}
+}
+
+// RevokeRefreshTokens revokes refresh tokens of family of user, or all refresh tokens of user
+// if familyID is empty, and denylists access tokens issued with them until they expire.
+func (u *UserStorage) RevokeRefreshTokens(ctx context.Context, userID uint64, familyID string) error {
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		return u.revokeRefreshTokens(ctx, tx, userID, familyID)
+	})
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// revokeRefreshTokens is RevokeRefreshTokens in tx, so it can be done together with change
+// which requires it.
+func (u *UserStorage) revokeRefreshTokens(ctx context.Context, tx pgx.Tx, userID uint64, familyID string) error {
+	SQLRevokeRefreshTokens :=
+		`UPDATE public."refresh_token" SET revoked_at = NOW()
+		 WHERE user_id = $1 AND ($2::TEXT = '' OR family_id = $2) AND revoked_at IS NULL;`
+
+	SQLRevokeAccessTokens :=
+		`INSERT INTO public."revoked_access_token" (jti, expires_at)
+		 SELECT access_jti, access_expires_at FROM public."refresh_token"
+		 WHERE user_id = $1 AND ($2::TEXT = '' OR family_id = $2) AND access_expires_at > NOW()
+		 ON CONFLICT (jti) DO NOTHING;`
+
+	SQLDeleteExpiredRevokedAccessTokens := `DELETE FROM public."revoked_access_token" WHERE expires_at <= NOW();`
+
+	for _, query := range []string{SQLRevokeRefreshTokens, SQLRevokeAccessTokens} {
+		if _, err := tx.Exec(ctx, query, userID, familyID); err != nil {
+			u.logger.Errorf("in revokeRefreshTokens: userID=%d familyID=%s err=%+v", userID, familyID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, SQLDeleteExpiredRevokedAccessTokens); err != nil {
+		u.logger.Errorf("in revokeRefreshTokens: err=%+v", err)
+
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// GetRevokedAccessTokens returns revoked access tokens which have not expired yet.
+func (u *UserStorage) GetRevokedAccessTokens(ctx context.Context) ([]*models.RevokedAccessToken, error) {
+	SQLGetRevokedAccessTokens :=
+		`SELECT jti, expires_at FROM public."revoked_access_token" WHERE expires_at > NOW();`
+
+	rows, err := u.pool.Query(ctx, SQLGetRevokedAccessTokens)
+	if err != nil {
+		u.logger.Errorf("in GetRevokedAccessTokens: err=%+v", err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	var (
+		curToken models.RevokedAccessToken
+		tokens   []*models.RevokedAccessToken
+	)
+
+	_, err = pgx.ForEachRow(rows, []any{&curToken.Jti, &curToken.ExpiresAt}, func() error {
+		tokens = append(tokens, &models.RevokedAccessToken{Jti: curToken.Jti, ExpiresAt: curToken.ExpiresAt})
+
+		return nil
+	})
+	if err != nil {
+		u.logger.Errorf("in GetRevokedAccessTokens: err=%+v", err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return tokens, nil
+}
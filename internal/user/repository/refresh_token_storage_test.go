@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"strings"
+	"testing"
+)
+
+// fakeRow scans its values into dest of the same types, or returns err.
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	for i, value := range r.values {
+		switch d := dest[i].(type) {
+		case *uint64:
+			*d = value.(uint64) //nolint:forcetypeassert
+		case *string:
+			*d = value.(string) //nolint:forcetypeassert
+		case *bool:
+			*d = value.(bool) //nolint:forcetypeassert
+		}
+	}
+
+	return nil
+}
+
+// fakeTx remembers statements and returns rows in order from QueryRow.
+type fakeTx struct {
+	pgx.Tx
+	rows    []fakeRow
+	queries []string
+}
+
+func (t *fakeTx) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	t.queries = append(t.queries, sql)
+
+	row := t.rows[0]
+	t.rows = t.rows[1:]
+
+	return row
+}
+
+func (t *fakeTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	t.queries = append(t.queries, sql)
+
+	return pgconn.CommandTag{}, nil
+}
+
+func TestUseRefreshTokenInTx(t *testing.T) {
+	t.Parallel()
+
+	noRows := fakeRow{values: nil, err: pgx.ErrNoRows}
+	// state rows are user id, family id, used, revoked
+	tests := []struct {
+		name        string
+		rows        []fakeRow
+		wantErr     error
+		wantRevoked bool
+	}{
+		{
+			name: "usable token",
+			rows: []fakeRow{{values: []any{uint64(1), uint64(7), "family", "login", false}, err: nil}},
+		},
+		{
+			name:        "reused token",
+			rows:        []fakeRow{noRows, {values: []any{uint64(7), "family", true, false}, err: nil}},
+			wantErr:     ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			name:    "revoked token",
+			rows:    []fakeRow{noRows, {values: []any{uint64(7), "family", true, true}, err: nil}},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name:    "expired token",
+			rows:    []fakeRow{noRows, {values: []any{uint64(7), "family", false, false}, err: nil}},
+			wantErr: ErrRefreshTokenExpired,
+		},
+		{
+			name:    "unknown token",
+			rows:    []fakeRow{noRows, noRows},
+			wantErr: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &UserStorage{pool: nil, logger: zap.NewNop().Sugar()}
+			tx := &fakeTx{rows: tt.rows} //nolint:exhaustruct
+
+			token, user, err := storage.useRefreshToken(context.Background(), tx, "hash")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && (token.FamilyID != "family" || user.ID != 7 || user.Login != "login") {
+				t.Errorf("token = %+v, user = %+v", token, user)
+			}
+
+			if len(tt.rows) > 1 && !strings.Contains(tx.queries[1], "FOR UPDATE") {
+				t.Errorf("state of token is read without lock: %s", tx.queries[1])
+			}
+
+			// family is revoked by statements of the same tx in which reuse is detected
+			revoked := false
+			for _, query := range tx.queries {
+				revoked = revoked || strings.Contains(query, "SET revoked_at = NOW()")
+			}
+
+			if revoked != tt.wantRevoked {
+				t.Errorf("family is revoked = %t, want %t", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
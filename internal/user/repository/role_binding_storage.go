@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	featurerepo "github.com/SanExpett/banners-backend/internal/feature/repository"
+	"github.com/SanExpett/banners-backend/internal/server/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrRoleBindingExists = myerrors.NewConflictError(nil, "role_binding_exists", myerrors.Message{
+		RU: "У пользователя уже есть эта роль на этой фиче",
+		EN: "User already has this role on this feature",
+	})
+	ErrRoleBindingNotFound = myerrors.NewNotFoundError("role_binding_not_found", myerrors.Message{
+		RU: "Привязка роли не найдена",
+		EN: "Role binding not found",
+	})
+
+	NameUniqRoleBinding        = "uniq_role_binding"            //nolint:gochecknoglobals
+	NameFkeyRoleBindingUser    = "role_binding_user_id_fkey"    //nolint:gochecknoglobals
+	NameFkeyRoleBindingFeature = "role_binding_feature_id_fkey" //nolint:gochecknoglobals
+)
+
+// AddRoleBinding gives role to user on feature, or on all features if FeatureID is 0.
+func (u *UserStorage) AddRoleBinding(ctx context.Context,
+	preBinding *models.PreRoleBinding) (*models.RoleBinding, error) {
+	SQLAddRoleBinding :=
+		`INSERT INTO public."role_binding" (user_id, role, feature_id) VALUES ($1, $2, NULLIF($3::BIGINT, 0))
+		 RETURNING id;`
+
+	binding := &models.RoleBinding{ //nolint:exhaustruct
+		UserID:    preBinding.UserID,
+		Role:      preBinding.Role,
+		FeatureID: preBinding.FeatureID,
+	}
+
+	bindingRow := u.pool.QueryRow(ctx, SQLAddRoleBinding, preBinding.UserID, preBinding.Role, preBinding.FeatureID)
+	if err := bindingRow.Scan(&binding.ID); err != nil {
+		switch {
+		case repository.IsPgConstraintErr(err, repository.PgErrCodeUniqueViolation, NameUniqRoleBinding):
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrRoleBindingExists)
+		case repository.IsPgConstraintErr(err, repository.PgErrCodeForeignKeyViolation, NameFkeyRoleBindingUser):
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrUserNotFound)
+		case repository.IsPgConstraintErr(err, repository.PgErrCodeForeignKeyViolation, NameFkeyRoleBindingFeature):
+			return nil, fmt.Errorf(myerrors.ErrTemplate, featurerepo.ErrFeatureNotFound)
+		}
+
+		u.logger.Errorf("in AddRoleBinding: preBinding=%+v err=%+v", preBinding, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return binding, nil
+}
+
+// GetRoleBindings returns role bindings of user ordered by id.
+func (u *UserStorage) GetRoleBindings(ctx context.Context, userID uint64) ([]*models.RoleBinding, error) {
+	SQLGetRoleBindings :=
+		`SELECT id, user_id, role, COALESCE(feature_id, 0) FROM public."role_binding"
+		 WHERE user_id = $1 ORDER BY id;`
+
+	bindingsRows, err := u.pool.Query(ctx, SQLGetRoleBindings, userID)
+	if err != nil {
+		u.logger.Errorf("in GetRoleBindings: userID=%d err=%+v", userID, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	curBinding := new(models.RoleBinding)
+
+	var bindings []*models.RoleBinding
+
+	_, err = pgx.ForEachRow(bindingsRows, []any{
+		&curBinding.ID, &curBinding.UserID, &curBinding.Role, &curBinding.FeatureID,
+	}, func() error {
+		bindings = append(bindings, &models.RoleBinding{
+			ID:        curBinding.ID,
+			UserID:    curBinding.UserID,
+			Role:      curBinding.Role,
+			FeatureID: curBinding.FeatureID,
+		})
+
+		return nil
+	})
+	if err != nil {
+		u.logger.Errorf("in GetRoleBindings: userID=%d err=%+v", userID, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bindings, nil
+}
+
+// DeleteRoleBinding deletes role binding and returns it. Refresh tokens of user are revoked
+// in the same transaction, so no session keeps permissions of role if it fails.
+func (u *UserStorage) DeleteRoleBinding(ctx context.Context, bindingID uint64) (*models.RoleBinding, error) {
+	SQLDeleteRoleBinding :=
+		`DELETE FROM public."role_binding" WHERE id = $1
+		 RETURNING id, user_id, role, COALESCE(feature_id, 0);`
+
+	binding := &models.RoleBinding{} //nolint:exhaustruct
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		bindingRow := tx.QueryRow(ctx, SQLDeleteRoleBinding, bindingID)
+		if err := bindingRow.Scan(&binding.ID, &binding.UserID, &binding.Role, &binding.FeatureID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf(myerrors.ErrTemplate, ErrRoleBindingNotFound)
+			}
+
+			u.logger.Errorf("in DeleteRoleBinding: bindingID=%d err=%+v", bindingID, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		return u.revokeRefreshTokens(ctx, tx, binding.UserID, "")
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return binding, nil
+}
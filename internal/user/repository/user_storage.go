@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"github.com/SanExpett/banners-backend/internal/server/repository"
 	"github.com/SanExpett/banners-backend/pkg/models"
 	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
 	"github.com/SanExpett/banners-backend/pkg/my_logger"
@@ -16,11 +15,25 @@ import (
 )
 
 var (
-	ErrLoginBusy     = myerrors.NewError("Такой логин уже занят")
-	ErrLoginNotExist = myerrors.NewError("Такой логин не существует")
-	ErrWrongPassword = myerrors.NewError("Некорректный пароль")
+	ErrLoginBusy = myerrors.NewConflictError(nil, "login_busy", myerrors.Message{
+		RU: "Такой логин уже занят",
+		EN: "Login is already taken",
+	})
+	ErrLoginNotExist = myerrors.NewUnauthorizedError("login_not_exist", myerrors.Message{
+		RU: "Такой логин не существует",
+		EN: "Login does not exist",
+	})
+	ErrWrongPassword = myerrors.NewUnauthorizedError("wrong_password", myerrors.Message{
+		RU: "Некорректный пароль",
+		EN: "Wrong password",
+	})
+	ErrUserNotFound = myerrors.NewNotFoundError("user_not_found", myerrors.Message{
+		RU: "Пользователь не найден",
+		EN: "User not found",
+	})
 
-	NameSeqUser = pgx.Identifier{"public", "user_id_seq"} //nolint:gochecknoglobals
+	ErrAdminLoginBusy = errors.New("login of first admin is already taken by user who is not admin, " +
+		"choose another ADMIN_LOGIN")
 )
 
 type UserStorage struct {
@@ -40,22 +53,22 @@ func NewUserStorage(pool *pgxpool.Pool) (*UserStorage, error) {
 	}, nil
 }
 
-func (u *UserStorage) createUser(ctx context.Context, tx pgx.Tx, preUser *models.PreUser) error {
+func (u *UserStorage) createUser(ctx context.Context, tx pgx.Tx, preUser *models.PreUser) (uint64, error) {
 	var SQLCreateUser string
 
-	var err error
-
-	SQLCreateUser = `INSERT INTO public."user" (login, password) VALUES ($1, $2);`
-	_, err = tx.Exec(ctx, SQLCreateUser,
+	SQLCreateUser = `INSERT INTO public."user" (login, password) VALUES ($1, $2) RETURNING id;`
+	userRow := tx.QueryRow(ctx, SQLCreateUser,
 		preUser.Login, preUser.Password)
 
-	if err != nil {
+	var userID uint64
+
+	if err := userRow.Scan(&userID); err != nil {
 		u.logger.Errorf("in createUser: preUser=%+v err=%+v", preUser, err)
 
-		return fmt.Errorf(myerrors.ErrTemplate, err)
+		return 0, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	return nil
+	return userID, nil
 }
 
 func (u *UserStorage) AddUser(ctx context.Context, preUser *models.PreUser) (*models.User, error) {
@@ -71,12 +84,7 @@ func (u *UserStorage) AddUser(ctx context.Context, preUser *models.PreUser) (*mo
 			return ErrLoginBusy
 		}
 
-		err = u.createUser(ctx, tx, preUser)
-		if err != nil {
-			return fmt.Errorf(myerrors.ErrTemplate, err)
-		}
-
-		id, err := repository.GetLastValSeq(ctx, tx, NameSeqUser)
+		id, err := u.createUser(ctx, tx, preUser)
 		if err != nil {
 			return fmt.Errorf(myerrors.ErrTemplate, err)
 		}
@@ -115,14 +123,14 @@ func (u *UserStorage) isLoginBusy(ctx context.Context, tx pgx.Tx, login string)
 }
 
 func (u *UserStorage) getUserByLogin(ctx context.Context, tx pgx.Tx, login string) (*models.User, error) {
-	SQLGetUserByLogin := `SELECT id, login, password FROM public."user" WHERE login=$1;`
+	SQLGetUserByLogin := `SELECT id, login, password, COALESCE(is_admin, FALSE) FROM public."user" WHERE login=$1;`
 	userLine := tx.QueryRow(ctx, SQLGetUserByLogin, login)
 
 	user := models.User{ //nolint:exhaustruct
 		Login: login,
 	}
 
-	if err := userLine.Scan(&user.ID, &user.Login, &user.Password); err != nil {
+	if err := userLine.Scan(&user.ID, &user.Login, &user.Password, &user.IsAdmin); err != nil {
 		u.logger.Errorln(err)
 
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
@@ -168,6 +176,92 @@ func (u *UserStorage) GetUser(ctx context.Context, login string, password string
 
 	userWithoutPass.ID = user.ID
 	userWithoutPass.Login = user.Login
+	userWithoutPass.IsAdmin = user.IsAdmin
 
 	return userWithoutPass, nil
 }
+
+// SetUserAdmin grants admin rights to user or revokes them. When rights are revoked, refresh tokens
+// of user are revoked in the same transaction, so no session keeps admin rights if it fails.
+func (u *UserStorage) SetUserAdmin(ctx context.Context, userID uint64, isAdmin bool) (*models.UserWithoutPassword, error) {
+	SQLSetUserAdmin := `UPDATE public."user" SET is_admin = $2 WHERE id = $1 RETURNING id, login, is_admin;`
+
+	user := &models.UserWithoutPassword{} //nolint:exhaustruct
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		userRow := tx.QueryRow(ctx, SQLSetUserAdmin, userID, isAdmin)
+		if err := userRow.Scan(&user.ID, &user.Login, &user.IsAdmin); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf(myerrors.ErrTemplate, ErrUserNotFound)
+			}
+
+			u.logger.Errorf("in SetUserAdmin: userID=%d isAdmin=%t err=%+v", userID, isAdmin, err)
+
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if isAdmin {
+			return nil
+		}
+
+		return u.revokeRefreshTokens(ctx, tx, userID, "")
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return user, nil
+}
+
+// AddFirstAdmin creates admin with login and password of preUser only if there is no admin yet,
+// otherwise it returns nil. Existing user is never made admin: if login is taken, ErrAdminLoginBusy
+// is returned. Concurrent calls from several instances of service are serialized.
+func (u *UserStorage) AddFirstAdmin(ctx context.Context, preUser *models.PreUser) (*models.UserWithoutPassword, error) {
+	SQLLockAdminBootstrap := `SELECT pg_advisory_xact_lock(hashtext('first_admin'));`
+
+	SQLAdminExists := `SELECT EXISTS (SELECT 1 FROM public."user" WHERE is_admin);`
+
+	SQLAddAdmin :=
+		`INSERT INTO public."user" (login, password, is_admin) VALUES ($1, $2, TRUE)
+		 RETURNING id, login, is_admin;`
+
+	var user *models.UserWithoutPassword
+
+	err := pgx.BeginFunc(ctx, u.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, SQLLockAdminBootstrap); err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		var adminExists bool
+
+		if err := tx.QueryRow(ctx, SQLAdminExists).Scan(&adminExists); err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if adminExists {
+			return nil
+		}
+
+		loginBusy, err := u.isLoginBusy(ctx, tx, preUser.Login)
+		if err != nil {
+			return fmt.Errorf(myerrors.ErrTemplate, err)
+		}
+
+		if loginBusy {
+			return ErrAdminLoginBusy
+		}
+
+		user = &models.UserWithoutPassword{} //nolint:exhaustruct
+
+		userRow := tx.QueryRow(ctx, SQLAddAdmin, preUser.Login, preUser.Password)
+
+		return userRow.Scan(&user.ID, &user.Login, &user.IsAdmin) //nolint:wrapcheck
+	})
+	if err != nil {
+		u.logger.Errorf("in AddFirstAdmin: login=%s err=%+v", preUser.Login, err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return user, nil
+}
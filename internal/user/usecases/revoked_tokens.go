@@ -0,0 +1,121 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	userrepo "github.com/SanExpett/banners-backend/internal/user/repository"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+const revokedTokensSweepInterval = time.Minute
+
+var _ IRevokedTokensStorage = (*userrepo.UserStorage)(nil)
+
+type IRevokedTokensStorage interface {
+	GetRevokedAccessTokens(ctx context.Context) ([]*models.RevokedAccessToken, error)
+}
+
+// RevokedTokens is in-memory denylist of access tokens revoked before they expired, so checking
+// token on request does not touch storage. It is loaded from storage on start and on reconnect
+// of listener, and tokens revoked by any instance of service are added by notifications.
+// Token is kept until it expires, after that it is rejected anyway.
+type RevokedTokens struct {
+	storage IRevokedTokensStorage
+	mu      sync.RWMutex
+	tokens  map[string]time.Time
+	logger  *zap.SugaredLogger
+}
+
+func NewRevokedTokens(storage IRevokedTokensStorage) (*RevokedTokens, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &RevokedTokens{ //nolint:exhaustruct
+		storage: storage,
+		tokens:  make(map[string]time.Time),
+		logger:  logger,
+	}, nil
+}
+
+// Load adds all revoked tokens from storage to denylist.
+func (r *RevokedTokens) Load(ctx context.Context) error {
+	tokens, err := r.storage.GetRevokedAccessTokens(ctx)
+	if err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range tokens {
+		r.tokens[token.Jti] = token.ExpiresAt
+	}
+
+	r.logger.Infof("in RevokedTokens.Load: %d revoked tokens are loaded", len(tokens))
+
+	return nil
+}
+
+// HandleReconnect reloads denylist, because revocations could be missed while listener was disconnected.
+func (r *RevokedTokens) HandleReconnect(ctx context.Context) {
+	if err := r.Load(ctx); err != nil {
+		r.logger.Errorf("in RevokedTokens.HandleReconnect: %+v", err)
+	}
+}
+
+// HandleRevoked adds token from payload of notification to denylist.
+func (r *RevokedTokens) HandleRevoked(payload string) {
+	token := &models.RevokedAccessToken{} //nolint:exhaustruct
+
+	if err := json.Unmarshal([]byte(payload), token); err != nil || token.Jti == "" {
+		r.logger.Errorf("in RevokedTokens.HandleRevoked: bad payload %s: %+v", payload, err)
+
+		return
+	}
+
+	r.mu.Lock()
+	r.tokens[token.Jti] = token.ExpiresAt
+	r.mu.Unlock()
+}
+
+func (r *RevokedTokens) IsAccessTokenRevoked(jti string) bool {
+	r.mu.RLock()
+	expiresAt, ok := r.tokens[jti]
+	r.mu.RUnlock()
+
+	return ok && time.Now().Before(expiresAt)
+}
+
+// Run deletes expired tokens from denylist until ctx is done.
+func (r *RevokedTokens) Run(ctx context.Context) {
+	ticker := time.NewTicker(revokedTokensSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.sweep(time.Now())
+		}
+	}
+}
+
+func (r *RevokedTokens) sweep(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for jti, expiresAt := range r.tokens {
+		if !now.Before(expiresAt) {
+			delete(r.tokens, jti)
+		}
+	}
+}
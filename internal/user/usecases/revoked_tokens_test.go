@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+type fakeRevokedTokensStorage struct {
+	tokens []*models.RevokedAccessToken
+	err    error
+}
+
+func (s *fakeRevokedTokensStorage) GetRevokedAccessTokens(context.Context) ([]*models.RevokedAccessToken, error) {
+	return s.tokens, s.err
+}
+
+func newTestRevokedTokens(t *testing.T, storage IRevokedTokensStorage) *RevokedTokens {
+	t.Helper()
+
+	revokedTokens, err := NewRevokedTokens(storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return revokedTokens
+}
+
+func revokedPayload(jti string, expiresAt time.Time) string {
+	return fmt.Sprintf(`{"jti": %q, "expires_at": %q}`, jti, expiresAt.Format(time.RFC3339Nano))
+}
+
+func TestRevokedTokensLoad(t *testing.T) {
+	t.Parallel()
+
+	future := time.Now().Add(time.Hour)
+	storage := &fakeRevokedTokensStorage{ //nolint:exhaustruct
+		tokens: []*models.RevokedAccessToken{
+			{Jti: "active", ExpiresAt: future},
+			{Jti: "expired", ExpiresAt: time.Now().Add(-time.Minute)},
+		},
+	}
+	revokedTokens := newTestRevokedTokens(t, storage)
+
+	if err := revokedTokens.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{"active": true, "expired": false, "unknown": false}
+	for jti, want := range tests {
+		if got := revokedTokens.IsAccessTokenRevoked(jti); got != want {
+			t.Errorf("IsAccessTokenRevoked(%q) = %t, want %t", jti, got, want)
+		}
+	}
+
+	storage.err = errors.New("storage is down")
+	if err := revokedTokens.Load(context.Background()); err == nil {
+		t.Error("Load() error = nil, want storage error")
+	}
+
+	if !revokedTokens.IsAccessTokenRevoked("active") {
+		t.Error("failed Load() dropped loaded tokens")
+	}
+}
+
+func TestRevokedTokensHandleRevoked(t *testing.T) {
+	t.Parallel()
+
+	revokedTokens := newTestRevokedTokens(t, &fakeRevokedTokensStorage{}) //nolint:exhaustruct
+	future := time.Now().Add(time.Hour)
+
+	revokedTokens.HandleRevoked(revokedPayload("jti", future))
+	revokedTokens.HandleRevoked(revokedPayload("expired", time.Now().Add(-time.Minute)))
+	revokedTokens.HandleRevoked(`{"jti": "bad", "expires_at": "tomorrow"}`)
+	revokedTokens.HandleRevoked(revokedPayload("", future))
+	revokedTokens.HandleRevoked("not json")
+
+	tests := map[string]bool{"jti": true, "expired": false, "bad": false, "": false}
+	for jti, want := range tests {
+		if got := revokedTokens.IsAccessTokenRevoked(jti); got != want {
+			t.Errorf("IsAccessTokenRevoked(%q) = %t, want %t", jti, got, want)
+		}
+	}
+}
+
+func TestRevokedTokensHandleReconnect(t *testing.T) {
+	t.Parallel()
+
+	storage := &fakeRevokedTokensStorage{} //nolint:exhaustruct
+	revokedTokens := newTestRevokedTokens(t, storage)
+
+	storage.tokens = []*models.RevokedAccessToken{{Jti: "missed", ExpiresAt: time.Now().Add(time.Hour)}}
+	revokedTokens.HandleReconnect(context.Background())
+
+	if !revokedTokens.IsAccessTokenRevoked("missed") {
+		t.Error("token revoked while listener was disconnected is not loaded on reconnect")
+	}
+}
+
+func TestRevokedTokensSweep(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	revokedTokens := newTestRevokedTokens(t, &fakeRevokedTokensStorage{}) //nolint:exhaustruct
+
+	revokedTokens.HandleRevoked(revokedPayload("active", now.Add(time.Hour)))
+	revokedTokens.HandleRevoked(revokedPayload("expired", now.Add(-time.Minute)))
+	revokedTokens.HandleRevoked(revokedPayload("expires_now", now))
+
+	revokedTokens.sweep(now)
+
+	if _, ok := revokedTokens.tokens["active"]; !ok || len(revokedTokens.tokens) != 1 {
+		t.Errorf("tokens after sweep = %v, want only active", revokedTokens.tokens)
+	}
+}
@@ -0,0 +1,48 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"io"
+)
+
+// AddRoleBinding gives role to user. User gets its permissions in tokens after refresh or new sign in.
+func (u *UserService) AddRoleBinding(ctx context.Context, r io.Reader) (*models.RoleBinding, error) {
+	preBinding, err := ValidatePreRoleBinding(r)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	binding, err := u.storage.AddRoleBinding(ctx, preBinding)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return binding, nil
+}
+
+func (u *UserService) GetRoleBindings(ctx context.Context, userID uint64) ([]*models.RoleBinding, error) {
+	bindings, err := u.storage.GetRoleBindings(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return bindings, nil
+}
+
+// DeleteRoleBinding takes role from user. All sessions of user are ended together with it,
+// so its tokens with permissions of role stop working at once.
+func (u *UserService) DeleteRoleBinding(ctx context.Context, bindingID uint64) (*models.RoleBinding, error) {
+	binding, err := u.storage.DeleteRoleBinding(ctx, bindingID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return binding, nil
+}
+
+func (u *UserService) GetRoles() []*models.Role {
+	return models.Roles()
+}
@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func (s *fakeUserStorage) AddRoleBinding(_ context.Context,
+	preBinding *models.PreRoleBinding) (*models.RoleBinding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	binding := &models.RoleBinding{
+		ID:        uint64(len(s.bindings) + 1),
+		UserID:    preBinding.UserID,
+		Role:      preBinding.Role,
+		FeatureID: preBinding.FeatureID,
+	}
+	s.bindings = append(s.bindings, binding)
+
+	return binding, nil
+}
+
+func (s *fakeUserStorage) DeleteRoleBinding(_ context.Context, bindingID uint64) (*models.RoleBinding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, binding := range s.bindings {
+		if binding.ID == bindingID {
+			s.bindings = slices.Delete(s.bindings, i, i+1)
+			s.revoke(binding.UserID, "")
+
+			return binding, nil
+		}
+	}
+
+	return nil, errors.New("no binding")
+}
+
+func TestUserServiceAddRoleBindingValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "valid", body: `{"user_id": 7, "role": "editor", "feature_id": 3}`},
+		{name: "all features", body: `{"user_id": 7, "role": "viewer"}`},
+		{name: "unknown role", body: `{"user_id": 7, "role": "owner"}`, wantErr: ErrWrongRoleBinding},
+		{name: "admin is not bindable", body: `{"user_id": 7, "role": "admin"}`, wantErr: ErrWrongRoleBinding},
+		{name: "no user", body: `{"role": "editor"}`, wantErr: ErrWrongRoleBinding},
+		{name: "bad json", body: `{"user_id": `, wantErr: ErrDecodeRoleBinding},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := newFakeUserStorage()
+			userService, _ := newTestUserService(t, storage)
+
+			_, err := userService.AddRoleBinding(context.Background(), strings.NewReader(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if (len(storage.bindings) == 1) != (tt.wantErr == nil) {
+				t.Errorf("%d bindings are stored", len(storage.bindings))
+			}
+		})
+	}
+}
+
+func TestUserServiceRoleBindingGrants(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := newFakeUserStorage()
+	userService, tokens := newTestUserService(t, storage)
+
+	binding, err := userService.AddRoleBinding(ctx, strings.NewReader(`{"user_id": 7, "role": "editor", "feature_id": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := userService.IssueTokens(ctx, storage.users[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, _ := accessPayload(t, tokens, storage, session.AccessToken)
+	principal := &models.Principal{Roles: []string{models.RoleUser}, Grants: payload.Grants} //nolint:exhaustruct
+
+	if !principal.Can(models.PermBannerWrite, 3) || principal.Can(models.PermBannerDelete, 3) ||
+		principal.Can(models.PermBannerRead, 4) {
+		t.Errorf("grants in token = %+v, want editor of feature 3", payload.Grants)
+	}
+
+	if _, err := userService.DeleteRoleBinding(ctx, binding.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, revoked := accessPayload(t, tokens, storage, session.AccessToken); !revoked {
+		t.Error("access token with grants of deleted role works")
+	}
+}
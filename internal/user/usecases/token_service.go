@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/jwt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/utils"
+	"io"
+	"time"
+)
+
+const (
+	sessionIDLen    = 16
+	refreshTokenLen = 32
+
+	TokenTypeBearer = "Bearer"
+)
+
+// IssueTokens starts new session of user and returns its access and refresh tokens.
+func (u *UserService) IssueTokens(ctx context.Context, user *models.UserWithoutPassword) (*models.Tokens, error) {
+	sessionID, err := utils.RandomString(sessionIDLen)
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return u.issueTokens(ctx, user, sessionID)
+}
+
+// RefreshTokens exchanges refresh token from body for new pair of tokens of the same session.
+// Refresh token is rotated: the presented one can not be used again.
+func (u *UserService) RefreshTokens(ctx context.Context, r io.Reader) (*models.Tokens, error) {
+	request, err := ValidateRefreshTokenRequest(r)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	token, user, err := u.storage.UseRefreshToken(ctx, utils.HashToken(request.RefreshToken))
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	user.Sanitize()
+
+	return u.issueTokens(ctx, user, token.FamilyID)
+}
+
+func (u *UserService) issueTokens(ctx context.Context, user *models.UserWithoutPassword,
+	sessionID string) (*models.Tokens, error) {
+	bindings, err := u.storage.GetRoleBindings(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	payload := &jwt.UserJwtPayload{ //nolint:exhaustruct
+		UserID:    user.ID,
+		Login:     user.Login,
+		IsAdmin:   user.IsAdmin,
+		Grants:    models.GrantsOf(bindings),
+		SessionID: sessionID,
+	}
+
+	accessToken, err := u.tokens.GenerateJwtToken(payload)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	refreshToken, err := utils.RandomString(refreshTokenLen)
+	if err != nil {
+		u.logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	err = u.storage.AddRefreshToken(ctx, &models.RefreshToken{ //nolint:exhaustruct
+		UserID:          user.ID,
+		FamilyID:        sessionID,
+		TokenHash:       utils.HashToken(refreshToken),
+		AccessJti:       payload.ID,
+		AccessExpiresAt: payload.ExpiresAt,
+		ExpiresAt:       time.Now().Add(u.refreshTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return &models.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    TokenTypeBearer,
+		ExpiresIn:    int64(u.tokens.TTL().Seconds()),
+	}, nil
+}
+
+// LogOut ends session of user: its refresh tokens are revoked and its access tokens are denylisted.
+func (u *UserService) LogOut(ctx context.Context, userID uint64, sessionID string) error {
+	// empty session id would revoke every session of user
+	if sessionID == "" {
+		return fmt.Errorf(myerrors.ErrTemplate, jwt.ErrInvalidToken)
+	}
+
+	if err := u.storage.RevokeRefreshTokens(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
+
+// LogOutEverywhere ends all sessions of user.
+func (u *UserService) LogOutEverywhere(ctx context.Context, userID uint64) error {
+	if err := u.storage.RevokeRefreshTokens(ctx, userID, ""); err != nil {
+		return fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	userrepo "github.com/SanExpett/banners-backend/internal/user/repository"
+	"github.com/SanExpett/banners-backend/pkg/jwt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	if _, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+type fakeRefreshToken struct {
+	token   models.RefreshToken
+	used    bool
+	revoked bool
+}
+
+// fakeUserStorage keeps refresh tokens in memory with the same rules as UserStorage.
+type fakeUserStorage struct {
+	IUserStorage
+	mu            sync.Mutex
+	users         map[uint64]*models.UserWithoutPassword
+	refreshTokens map[string]*fakeRefreshToken
+	revokedJtis   map[string]bool
+	addedAdmin    *models.PreUser
+	bindings      []*models.RoleBinding
+}
+
+func newFakeUserStorage() *fakeUserStorage {
+	return &fakeUserStorage{ //nolint:exhaustruct
+		users: map[uint64]*models.UserWithoutPassword{
+			7: {ID: 7, Login: "user", IsAdmin: false}, //nolint:exhaustruct
+		},
+		refreshTokens: make(map[string]*fakeRefreshToken),
+		revokedJtis:   make(map[string]bool),
+	}
+}
+
+func (s *fakeUserStorage) AddRefreshToken(_ context.Context, token *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.refreshTokens {
+		if stored.token.FamilyID == token.FamilyID && stored.revoked {
+			return userrepo.ErrRefreshTokenReused
+		}
+	}
+
+	s.refreshTokens[token.TokenHash] = &fakeRefreshToken{token: *token} //nolint:exhaustruct
+
+	return nil
+}
+
+func (s *fakeUserStorage) UseRefreshToken(_ context.Context,
+	tokenHash string) (*models.RefreshToken, *models.UserWithoutPassword, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.refreshTokens[tokenHash]
+
+	switch {
+	case !ok || stored.revoked:
+		return nil, nil, userrepo.ErrInvalidRefreshToken
+	case stored.used:
+		s.revoke(stored.token.UserID, stored.token.FamilyID)
+
+		return nil, nil, userrepo.ErrRefreshTokenReused
+	}
+
+	stored.used = true
+	user := *s.users[stored.token.UserID]
+	token := stored.token
+
+	return &token, &user, nil
+}
+
+func (s *fakeUserStorage) RevokeRefreshTokens(_ context.Context, userID uint64, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoke(userID, familyID)
+
+	return nil
+}
+
+func (s *fakeUserStorage) revoke(userID uint64, familyID string) {
+	for _, stored := range s.refreshTokens {
+		if stored.token.UserID == userID && (familyID == "" || stored.token.FamilyID == familyID) {
+			stored.revoked = true
+			s.revokedJtis[stored.token.AccessJti] = true
+		}
+	}
+}
+
+func (s *fakeUserStorage) GetRoleBindings(_ context.Context, userID uint64) ([]*models.RoleBinding, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var bindings []*models.RoleBinding
+
+	for _, binding := range s.bindings {
+		if binding.UserID == userID {
+			bindings = append(bindings, binding)
+		}
+	}
+
+	return bindings, nil
+}
+
+func (s *fakeUserStorage) isAccessTokenRevoked(jti string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.revokedJtis[jti]
+}
+
+func newTestUserService(t *testing.T, storage IUserStorage) (*UserService, *jwt.Manager) {
+	t.Helper()
+
+	signing, err := jwt.NewSigningKey("hs", jwt.AlgHS256, []byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := jwt.NewKeySet(signing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := jwt.NewManager(keySet, "issuer", "audience", time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userService, err := NewUserService(storage, tokens, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return userService, tokens
+}
+
+func refreshRequest(refreshToken string) *strings.Reader {
+	return strings.NewReader(fmt.Sprintf(`{"refresh_token": %q}`, refreshToken))
+}
+
+// accessPayload verifies access token and tells if it is revoked.
+func accessPayload(t *testing.T, tokens *jwt.Manager, storage *fakeUserStorage,
+	accessToken string) (*jwt.UserJwtPayload, bool) {
+	t.Helper()
+
+	payload, err := tokens.NewUserJwtPayload(accessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return payload, storage.isAccessTokenRevoked(payload.ID)
+}
+
+func TestUserServiceRefreshTokensRotation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := newFakeUserStorage()
+	userService, tokens := newTestUserService(t, storage)
+
+	first, err := userService.IssueTokens(ctx, storage.users[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := storage.refreshTokens[first.RefreshToken]; ok {
+		t.Error("refresh token is stored as is, not its hash")
+	}
+
+	second, err := userService.RefreshTokens(ctx, refreshRequest(first.RefreshToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Error("tokens are not rotated")
+	}
+
+	firstPayload, _ := accessPayload(t, tokens, storage, first.AccessToken)
+	secondPayload, _ := accessPayload(t, tokens, storage, second.AccessToken)
+
+	if firstPayload.SessionID == "" || firstPayload.SessionID != secondPayload.SessionID {
+		t.Errorf("session of refreshed tokens = %q, want %q", secondPayload.SessionID, firstPayload.SessionID)
+	}
+
+	if second.TokenType != TokenTypeBearer || second.ExpiresIn != int64(time.Minute.Seconds()) {
+		t.Errorf("tokens = %+v", second)
+	}
+
+	if _, err := userService.RefreshTokens(ctx, refreshRequest("unknown")); !errors.Is(err,
+		userrepo.ErrInvalidRefreshToken) {
+		t.Errorf("err for unknown token = %v, want %v", err, userrepo.ErrInvalidRefreshToken)
+	}
+
+	if _, err := userService.RefreshTokens(ctx, strings.NewReader(`{}`)); !errors.Is(err,
+		userrepo.ErrInvalidRefreshToken) {
+		t.Errorf("err for empty token = %v, want %v", err, userrepo.ErrInvalidRefreshToken)
+	}
+}
+
+func TestUserServiceRefreshTokenReuse(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := newFakeUserStorage()
+	userService, tokens := newTestUserService(t, storage)
+
+	first, err := userService.IssueTokens(ctx, storage.users[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherSession, err := userService.IssueTokens(ctx, storage.users[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := userService.RefreshTokens(ctx, refreshRequest(first.RefreshToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first token is presented again, so it was stolen
+	if _, err := userService.RefreshTokens(ctx, refreshRequest(first.RefreshToken)); !errors.Is(err,
+		userrepo.ErrRefreshTokenReused) {
+		t.Fatalf("err for reused token = %v, want %v", err, userrepo.ErrRefreshTokenReused)
+	}
+
+	if _, err := userService.RefreshTokens(ctx, refreshRequest(second.RefreshToken)); !errors.Is(err,
+		userrepo.ErrInvalidRefreshToken) {
+		t.Errorf("err for rotated token of revoked session = %v, want %v", err, userrepo.ErrInvalidRefreshToken)
+	}
+
+	for name, accessToken := range map[string]string{"first": first.AccessToken, "second": second.AccessToken} {
+		if _, revoked := accessPayload(t, tokens, storage, accessToken); !revoked {
+			t.Errorf("%s access token of revoked session is not denylisted", name)
+		}
+	}
+
+	if _, revoked := accessPayload(t, tokens, storage, otherSession.AccessToken); revoked {
+		t.Error("access token of other session is denylisted")
+	}
+
+	if _, err := userService.RefreshTokens(ctx, refreshRequest(otherSession.RefreshToken)); err != nil {
+		t.Errorf("other session can not be refreshed: %v", err)
+	}
+}
+
+func TestUserServiceLogOut(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := newFakeUserStorage()
+	userService, tokens := newTestUserService(t, storage)
+
+	current, err := userService.IssueTokens(ctx, storage.users[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := userService.IssueTokens(ctx, storage.users[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, _ := accessPayload(t, tokens, storage, current.AccessToken)
+
+	if err := userService.LogOut(ctx, 7, ""); !errors.Is(err, jwt.ErrInvalidToken) {
+		t.Errorf("err for empty session = %v, want %v", err, jwt.ErrInvalidToken)
+	}
+
+	if err := userService.LogOut(ctx, 7, payload.SessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, revoked := accessPayload(t, tokens, storage, current.AccessToken); !revoked {
+		t.Error("access token is not denylisted on log out")
+	}
+
+	if _, err := userService.RefreshTokens(ctx, refreshRequest(current.RefreshToken)); err == nil {
+		t.Error("refresh token works after log out")
+	}
+
+	if _, revoked := accessPayload(t, tokens, storage, other.AccessToken); revoked {
+		t.Error("access token of other session is denylisted")
+	}
+
+	if err := userService.LogOutEverywhere(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, revoked := accessPayload(t, tokens, storage, other.AccessToken); !revoked {
+		t.Error("access token of other session is not denylisted on log out everywhere")
+	}
+
+	myErr := &myerrors.Error{}
+	if _, err := userService.RefreshTokens(ctx, refreshRequest(other.RefreshToken)); !errors.As(err, &myErr) ||
+		myErr.Kind() != myerrors.KindUnauthorized {
+		t.Errorf("err for refresh after log out everywhere = %v, want unauthorized", err)
+	}
+}
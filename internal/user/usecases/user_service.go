@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	userrepo "github.com/SanExpett/banners-backend/internal/user/repository"
+	"github.com/SanExpett/banners-backend/pkg/jwt"
 	"github.com/SanExpett/banners-backend/pkg/models"
 	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
 	"github.com/SanExpett/banners-backend/pkg/my_logger"
 	"github.com/SanExpett/banners-backend/pkg/utils"
 	"go.uber.org/zap"
 	"io"
+	"time"
 )
 
 var _ IUserStorage = (*userrepo.UserStorage)(nil)
@@ -17,20 +19,30 @@ var _ IUserStorage = (*userrepo.UserStorage)(nil)
 type IUserStorage interface {
 	AddUser(ctx context.Context, preUser *models.PreUser) (*models.User, error)
 	GetUser(ctx context.Context, login string, password string) (*models.UserWithoutPassword, error)
+	AddRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	UseRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, *models.UserWithoutPassword, error)
+	RevokeRefreshTokens(ctx context.Context, userID uint64, familyID string) error
+	SetUserAdmin(ctx context.Context, userID uint64, isAdmin bool) (*models.UserWithoutPassword, error)
+	AddFirstAdmin(ctx context.Context, preUser *models.PreUser) (*models.UserWithoutPassword, error)
+	AddRoleBinding(ctx context.Context, preBinding *models.PreRoleBinding) (*models.RoleBinding, error)
+	GetRoleBindings(ctx context.Context, userID uint64) ([]*models.RoleBinding, error)
+	DeleteRoleBinding(ctx context.Context, bindingID uint64) (*models.RoleBinding, error)
 }
 
 type UserService struct {
-	storage IUserStorage
-	logger  *zap.SugaredLogger
+	storage    IUserStorage
+	tokens     *jwt.Manager
+	refreshTTL time.Duration
+	logger     *zap.SugaredLogger
 }
 
-func NewUserService(userStorage IUserStorage) (*UserService, error) {
+func NewUserService(userStorage IUserStorage, tokens *jwt.Manager, refreshTTL time.Duration) (*UserService, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, err
 	}
 
-	return &UserService{storage: userStorage, logger: logger}, nil
+	return &UserService{storage: userStorage, tokens: tokens, refreshTTL: refreshTTL, logger: logger}, nil
 }
 
 func (u *UserService) AddUser(ctx context.Context, r io.Reader) (*models.User, error) {
@@ -67,3 +79,44 @@ func (u *UserService) GetUser(ctx context.Context, login string, password string
 
 	return user, nil
 }
+
+// SetUserAdmin grants admin rights to user or revokes them on behalf of admin with actorID.
+// Admin can not revoke own rights, so there is always someone to grant them back. When rights
+// are revoked, all sessions of user are ended together with it, so its tokens with admin rights
+// stop working at once.
+func (u *UserService) SetUserAdmin(ctx context.Context, actorID uint64, userID uint64,
+	isAdmin bool) (*models.UserWithoutPassword, error) {
+	if actorID == userID && !isAdmin {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrRevokeOwnAdmin)
+	}
+
+	user, err := u.storage.SetUserAdmin(ctx, userID, isAdmin)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	user.Sanitize()
+
+	return user, nil
+}
+
+// AddFirstAdmin creates first admin on start of service if there is no admin yet, otherwise it
+// returns nil. It fails if login is taken by user who is not admin, existing users are never made admin.
+func (u *UserService) AddFirstAdmin(ctx context.Context, login string, password string) (*models.UserWithoutPassword, error) {
+	preUser, err := ValidateUserCredentials(login, password)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	preUser.Password, err = utils.HashPass(preUser.Password)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	user, err := u.storage.AddFirstAdmin(ctx, preUser)
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"github.com/SanExpett/banners-backend/pkg/utils"
+	"testing"
+)
+
+func (s *fakeUserStorage) SetUserAdmin(_ context.Context, userID uint64,
+	isAdmin bool) (*models.UserWithoutPassword, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[userID].IsAdmin = isAdmin
+	user := *s.users[userID]
+
+	// storage ends sessions together with revocation of rights
+	if !isAdmin {
+		s.revoke(userID, "")
+	}
+
+	return &user, nil
+}
+
+func (s *fakeUserStorage) AddFirstAdmin(_ context.Context,
+	preUser *models.PreUser) (*models.UserWithoutPassword, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.IsAdmin {
+			return nil, nil
+		}
+	}
+
+	s.addedAdmin = preUser
+	admin := &models.UserWithoutPassword{ID: uint64(len(s.users) + 1), Login: preUser.Login, IsAdmin: true}
+	s.users[admin.ID] = admin
+
+	return admin, nil
+}
+
+func TestUserServiceSetUserAdmin(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := newFakeUserStorage()
+	userService, tokens := newTestUserService(t, storage)
+
+	if _, err := userService.SetUserAdmin(ctx, 7, 7, false); !errors.Is(err, ErrRevokeOwnAdmin) {
+		t.Fatalf("err for revoking own rights = %v, want %v", err, ErrRevokeOwnAdmin)
+	}
+
+	user, err := userService.SetUserAdmin(ctx, 1, 7, true)
+	if err != nil || !user.IsAdmin {
+		t.Fatalf("SetUserAdmin(true) = %+v, %v", user, err)
+	}
+
+	session, err := userService.IssueTokens(ctx, storage.users[7])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := userService.SetUserAdmin(ctx, 1, 7, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, revoked := accessPayload(t, tokens, storage, session.AccessToken); revoked {
+		t.Error("sessions are ended when rights are granted")
+	}
+
+	user, err = userService.SetUserAdmin(ctx, 1, 7, false)
+	if err != nil || user.IsAdmin {
+		t.Fatalf("SetUserAdmin(false) = %+v, %v", user, err)
+	}
+
+	if _, revoked := accessPayload(t, tokens, storage, session.AccessToken); !revoked {
+		t.Error("access token with admin rights works after rights are revoked")
+	}
+}
+
+func TestUserServiceAddFirstAdmin(t *testing.T) {
+	t.Parallel()
+
+	storage := newFakeUserStorage()
+	userService, _ := newTestUserService(t, storage)
+
+	_, err := userService.AddFirstAdmin(context.Background(), "admin", "short")
+	if !errors.Is(err, ErrWrongCredentials) {
+		t.Errorf("err for short password = %v, want %v", err, ErrWrongCredentials)
+	}
+
+	admin, err := userService.AddFirstAdmin(context.Background(), " admin ", "password")
+	if err != nil || !admin.IsAdmin {
+		t.Fatalf("AddAdmin() = %+v, %v", admin, err)
+	}
+
+	if storage.addedAdmin.Login != "admin" {
+		t.Errorf("login is not trimmed: %q", storage.addedAdmin.Login)
+	}
+
+	passHash, err := hex.DecodeString(storage.addedAdmin.Password)
+	if err != nil || !utils.ComparePassAndHash(passHash, "password") {
+		t.Errorf("password is not stored as its hash: %q", storage.addedAdmin.Password)
+	}
+
+	second, err := userService.AddFirstAdmin(context.Background(), "other", "password")
+	if err != nil || second != nil {
+		t.Errorf("AddFirstAdmin() with admin present = %+v, %v, want nil, nil", second, err)
+	}
+}
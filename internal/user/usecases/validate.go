@@ -3,6 +3,7 @@ package usecases
 import (
 	"encoding/json"
 	"fmt"
+	userrepo "github.com/SanExpett/banners-backend/internal/user/repository"
 	"github.com/SanExpett/banners-backend/pkg/models"
 	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
 	"github.com/SanExpett/banners-backend/pkg/my_logger"
@@ -11,10 +12,32 @@ import (
 )
 
 var (
-	ErrWrongCredentials = myerrors.NewError("Некорректный логин (должен быть длиной от 1 до 25 " +
-		"символов) или пароль (должен быть не менее 6 символов, содержать цифры, " +
-		"строчные и заглавные буквы и специальные символы)")
-	ErrDecodeUser = myerrors.NewError("Некорректный json пользователя")
+	ErrWrongCredentials = myerrors.NewError("wrong_credentials", myerrors.Message{
+		RU: "Некорректный логин (должен быть длиной от 1 до 25 символов) или пароль (должен быть " +
+			"не менее 6 символов, содержать цифры, строчные и заглавные буквы и специальные символы)",
+		EN: "Invalid login (must be from 1 to 25 characters long) or password (must be at least " +
+			"6 characters long and contain digits, lowercase and uppercase letters and special characters)",
+	})
+	ErrDecodeUser = myerrors.NewError("bad_user_json", myerrors.Message{
+		RU: "Некорректный json пользователя",
+		EN: "Invalid json of user",
+	})
+	ErrDecodeRefreshToken = myerrors.NewError("bad_refresh_token_json", myerrors.Message{
+		RU: "Некорректный json refresh токена",
+		EN: "Invalid json of refresh token",
+	})
+	ErrRevokeOwnAdmin = myerrors.NewError("revoke_own_admin", myerrors.Message{
+		RU: "Нельзя отозвать права админа у самого себя",
+		EN: "Admin can not revoke own admin rights",
+	})
+	ErrDecodeRoleBinding = myerrors.NewError("bad_role_binding_json", myerrors.Message{
+		RU: "Некорректный json привязки роли",
+		EN: "Invalid json of role binding",
+	})
+	ErrWrongRoleBinding = myerrors.NewError("wrong_role_binding", myerrors.Message{
+		RU: "Привязка роли должна содержать user_id и role (viewer, editor или manager)",
+		EN: "Role binding must contain user_id and role (viewer, editor or manager)",
+	})
 )
 
 func ValidatePreUser(r io.Reader) (*models.PreUser, error) {
@@ -42,6 +65,51 @@ func ValidatePreUser(r io.Reader) (*models.PreUser, error) {
 	return preUser, nil
 }
 
+func ValidateRefreshTokenRequest(r io.Reader) (*models.RefreshTokenRequest, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	decoder := json.NewDecoder(r)
+
+	request := new(models.RefreshTokenRequest)
+	if err := decoder.Decode(request); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodeRefreshToken)
+	}
+
+	if request.RefreshToken == "" {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, userrepo.ErrInvalidRefreshToken)
+	}
+
+	return request, nil
+}
+
+func ValidatePreRoleBinding(r io.Reader) (*models.PreRoleBinding, error) {
+	logger, err := my_logger.Get()
+	if err != nil {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	decoder := json.NewDecoder(r)
+
+	preBinding := new(models.PreRoleBinding)
+	if err := decoder.Decode(preBinding); err != nil {
+		logger.Errorln(err)
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrDecodeRoleBinding)
+	}
+
+	_, err = govalidator.ValidateStruct(preBinding)
+	if err != nil || !models.IsBindableRole(preBinding.Role) {
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrWrongRoleBinding)
+	}
+
+	return preBinding, nil
+}
+
 func ValidateUserCredentials(login string, password string) (*models.PreUser, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
@@ -53,7 +121,7 @@ func ValidateUserCredentials(login string, password string) (*models.PreUser, er
 	preUser.Login = login
 	preUser.Password = password
 	preUser.Trim()
-	logger.Infoln(preUser)
+	logger.Infoln(preUser.Login)
 
 	_, err = govalidator.ValidateStruct(preUser)
 	if err != nil && (govalidator.ErrorByField(err, "login") != "" ||
@@ -1,6 +1,12 @@
 package config
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
 
 const (
 	standardAllowOrigin        = "localhost:3000"
@@ -10,6 +16,20 @@ const (
 	standardPathToRoot         = "."
 	standardOutputLogPath      = "stdout /var/log/backend/logs.json"
 	standardErrorOutputLogPath = "stderr /var/log/backend/err_logs.json"
+	standardBannerCacheMode    = "index"
+	standardBannerCacheTTL     = 5 * time.Minute
+	standardBannerIndexRefresh = time.Minute
+	standardBannerVersionsLim  = 4
+	standardBannerDeleteBatch  = 1000
+	standardBannerDeletePoll   = 10 * time.Second
+	standardLegacyErrorStatus  = false
+	standardJwtIssuer          = "banners-backend"
+	standardJwtAudience        = "banners-backend"
+	standardJwtTTL             = 15 * time.Minute
+	standardJwtRefreshTTL      = 30 * 24 * time.Hour
+	standardJwtLeeway          = 30 * time.Second
+	standardJwtSigningKeyID    = "default"
+	standardJwtSigningAlg      = "HS256"
 
 	envAllowOrigin        = "ALLOW_ORIGIN"
 	envSchema             = "SCHEMA"
@@ -18,6 +38,25 @@ const (
 	envPathToRoot         = "PATH_TO_ROOT"
 	envOutputLogPath      = "OUTPUT_LOG_PATH"
 	envErrorOutputLogPath = "ERROR_OUTPUT_LOG_PATH"
+	envBannerCacheMode    = "BANNER_CACHE_MODE"
+	envBannerCacheTTL     = "BANNER_CACHE_TTL"
+	envBannerIndexRefresh = "BANNER_INDEX_REFRESH_INTERVAL"
+	envBannerVersionsLim  = "BANNER_VERSIONS_LIMIT"
+	envBannerDeleteBatch  = "BANNER_DELETE_BATCH_SIZE"
+	envBannerDeletePoll   = "BANNER_DELETE_POLL_INTERVAL"
+	envLegacyErrorStatus  = "LEGACY_ERROR_STATUS"
+	envJwtIssuer          = "JWT_ISSUER"
+	envJwtAudience        = "JWT_AUDIENCE"
+	envJwtTTL             = "JWT_TTL"
+	envJwtRefreshTTL      = "JWT_REFRESH_TTL"
+	envJwtLeeway          = "JWT_LEEWAY"
+	envJwtSigningKeyID    = "JWT_SIGNING_KEY_ID"
+	envJwtSigningAlg      = "JWT_SIGNING_ALG"
+	envJwtSigningKey      = "JWT_SIGNING_KEY"
+	envJwtSigningKeyFile  = "JWT_SIGNING_KEY_FILE"
+	envJwtVerifyKeys      = "JWT_VERIFY_KEYS"
+	envAdminLogin         = "ADMIN_LOGIN"
+	envAdminPassword      = "ADMIN_PASSWORD"
 )
 
 type Config struct {
@@ -28,18 +67,129 @@ type Config struct {
 	PathToRoot         string
 	OutputLogPath      string
 	ErrorOutputLogPath string
+	// BannerCacheMode is "index" for full in-memory index of banners or "ttl" for per-key cache
+	BannerCacheMode    string
+	BannerCacheTTL     time.Duration
+	BannerIndexRefresh time.Duration
+	// BannerVersionsLimit is how many latest versions of banner are kept, including the current one
+	BannerVersionsLimit uint64
+	// BannerDeleteBatch is how many banners are deleted by one transaction of delete job
+	BannerDeleteBatch uint64
+	BannerDeletePoll  time.Duration
+	// LegacyErrorStatus makes all errors be answered with HTTP status 222, real status is only in body
+	LegacyErrorStatus bool
+	// JwtIssuer and JwtAudience are put into iss and aud of issued tokens, other tokens are rejected
+	JwtIssuer   string
+	JwtAudience string
+	JwtTTL      time.Duration
+	// JwtRefreshTTL is lifetime of refresh tokens, user has to sign in again after it
+	JwtRefreshTTL time.Duration
+	// JwtLeeway is allowed clock skew when exp, nbf and iat of token are checked
+	JwtLeeway time.Duration
+	// JwtSigningKeyID is kid of key which signs new tokens, JwtSigningAlg is HS256, RS256 or EdDSA
+	JwtSigningKeyID string
+	JwtSigningAlg   string
+	// JwtSigningKey is HMAC secret or PEM of private key, JwtSigningKeyFile is path to it and wins if set
+	JwtSigningKey     string
+	JwtSigningKeyFile string
+	// JwtVerifyKeys are previous keys which only verify tokens, comma separated kid:alg:path
+	JwtVerifyKeys string
+	// AdminLogin and AdminPassword are credentials of first admin, which is created on start if AdminLogin is set
+	AdminLogin    string
+	AdminPassword string
 }
 
-func New() *Config {
-	return &Config{
-		AllowOrigin:        getEnvStr(envAllowOrigin, standardAllowOrigin),
-		Schema:             getEnvStr(envSchema, standardSchema),
-		PortServer:         getEnvStr(envPortBackend, standardPort),
-		URLDataBase:        getEnvStr(envURLDataBase, standardURLDataBase),
-		PathToRoot:         getEnvStr(envPathToRoot, standardPathToRoot),
-		OutputLogPath:      getEnvStr(envOutputLogPath, standardOutputLogPath),
-		ErrorOutputLogPath: getEnvStr(envErrorOutputLogPath, standardErrorOutputLogPath),
+var (
+	ErrNoJwtSigningKey = errors.New("jwt signing key is not set, set " + envJwtSigningKey +
+		" or " + envJwtSigningKeyFile)
+	ErrNotPositiveDuration = errors.New("duration must be positive")
+	ErrNegativeDuration    = errors.New("duration must not be negative")
+	ErrBadDuration         = errors.New("duration must be like 30s, 15m or 1h")
+)
+
+// New loads config from environment. It fails if there is no key to sign tokens, there is no
+// default one, so tokens can not be forged with a well-known secret. Malformed durations are
+// not replaced with defaults either, so a typo does not pass unnoticed.
+func New() (*Config, error) {
+	var durationErrs []error
+
+	getDuration := func(name string, defaultValue time.Duration) time.Duration {
+		duration, err := getEnvDuration(name, defaultValue)
+		if err != nil {
+			durationErrs = append(durationErrs, err)
+		}
+
+		return duration
+	}
+
+	config := &Config{
+		AllowOrigin:         getEnvStr(envAllowOrigin, standardAllowOrigin),
+		Schema:              getEnvStr(envSchema, standardSchema),
+		PortServer:          getEnvStr(envPortBackend, standardPort),
+		URLDataBase:         getEnvStr(envURLDataBase, standardURLDataBase),
+		PathToRoot:          getEnvStr(envPathToRoot, standardPathToRoot),
+		OutputLogPath:       getEnvStr(envOutputLogPath, standardOutputLogPath),
+		ErrorOutputLogPath:  getEnvStr(envErrorOutputLogPath, standardErrorOutputLogPath),
+		BannerCacheMode:     getEnvStr(envBannerCacheMode, standardBannerCacheMode),
+		BannerCacheTTL:      getDuration(envBannerCacheTTL, standardBannerCacheTTL),
+		BannerIndexRefresh:  getDuration(envBannerIndexRefresh, standardBannerIndexRefresh),
+		BannerVersionsLimit: getEnvUint64(envBannerVersionsLim, standardBannerVersionsLim),
+		BannerDeleteBatch:   getEnvUint64(envBannerDeleteBatch, standardBannerDeleteBatch),
+		BannerDeletePoll:    getDuration(envBannerDeletePoll, standardBannerDeletePoll),
+		LegacyErrorStatus:   getEnvBool(envLegacyErrorStatus, standardLegacyErrorStatus),
+		JwtIssuer:           getEnvStr(envJwtIssuer, standardJwtIssuer),
+		JwtAudience:         getEnvStr(envJwtAudience, standardJwtAudience),
+		JwtTTL:              getDuration(envJwtTTL, standardJwtTTL),
+		JwtRefreshTTL:       getDuration(envJwtRefreshTTL, standardJwtRefreshTTL),
+		JwtLeeway:           getDuration(envJwtLeeway, standardJwtLeeway),
+		JwtSigningKeyID:     getEnvStr(envJwtSigningKeyID, standardJwtSigningKeyID),
+		JwtSigningAlg:       getEnvStr(envJwtSigningAlg, standardJwtSigningAlg),
+		JwtSigningKey:       getEnvStr(envJwtSigningKey, ""),
+		JwtSigningKeyFile:   getEnvStr(envJwtSigningKeyFile, ""),
+		JwtVerifyKeys:       getEnvStr(envJwtVerifyKeys, ""),
+		AdminLogin:          getEnvStr(envAdminLogin, ""),
+		AdminPassword:       getEnvStr(envAdminPassword, ""),
 	}
+
+	if err := errors.Join(durationErrs...); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return config, nil
+}
+
+func (c *Config) validate() error {
+	if c.JwtSigningKey == "" && c.JwtSigningKeyFile == "" {
+		return ErrNoJwtSigningKey
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{name: envBannerCacheTTL, value: c.BannerCacheTTL},
+		{name: envBannerIndexRefresh, value: c.BannerIndexRefresh},
+		{name: envBannerDeletePoll, value: c.BannerDeletePoll},
+		{name: envJwtTTL, value: c.JwtTTL},
+		{name: envJwtRefreshTTL, value: c.JwtRefreshTTL},
+	}
+
+	for _, duration := range durations {
+		if duration.value <= 0 {
+			return fmt.Errorf("%w: %s=%s", ErrNotPositiveDuration, duration.name, duration.value)
+		}
+	}
+
+	// zero leeway only means that clocks of services are not expected to drift
+	if c.JwtLeeway < 0 {
+		return fmt.Errorf("%w: %s=%s", ErrNegativeDuration, envJwtLeeway, c.JwtLeeway)
+	}
+
+	return nil
 }
 
 func getEnvStr(name string, defaultValue string) string {
@@ -50,3 +200,45 @@ func getEnvStr(name string, defaultValue string) string {
 
 	return result
 }
+
+func getEnvDuration(name string, defaultValue time.Duration) (time.Duration, error) {
+	result, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(result)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s=%q", ErrBadDuration, name, result)
+	}
+
+	return duration, nil
+}
+
+func getEnvUint64(name string, defaultValue uint64) uint64 {
+	result, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+
+	number, err := strconv.ParseUint(result, 10, 64)
+	if err != nil || number == 0 {
+		return defaultValue
+	}
+
+	return number
+}
+
+func getEnvBool(name string, defaultValue bool) bool {
+	result, ok := os.LookupEnv(name)
+	if !ok {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(result)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests of config are not parallel, because they set environment of process.
+
+func TestNewRequiresSigningKey(t *testing.T) {
+	t.Setenv(envJwtSigningKey, "")
+	t.Setenv(envJwtSigningKeyFile, "")
+
+	if _, err := New(); !errors.Is(err, ErrNoJwtSigningKey) {
+		t.Errorf("err = %v, want %v", err, ErrNoJwtSigningKey)
+	}
+
+	t.Setenv(envJwtSigningKeyFile, "/run/secrets/jwt.pem")
+
+	if _, err := New(); err != nil {
+		t.Errorf("config with key file: %v", err)
+	}
+
+	t.Setenv(envJwtSigningKeyFile, "")
+	t.Setenv(envJwtSigningKey, "secret")
+
+	config, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.JwtSigningKey != "secret" {
+		t.Errorf("JwtSigningKey = %q, want %q", config.JwtSigningKey, "secret")
+	}
+}
+
+func TestNewRejectsNotPositiveDurations(t *testing.T) {
+	t.Setenv(envJwtSigningKey, "secret")
+
+	names := []string{envBannerIndexRefresh, envBannerDeletePoll, envBannerCacheTTL, envJwtTTL, envJwtRefreshTTL}
+
+	for _, name := range names {
+		for _, value := range []string{"0s", "-1m"} {
+			t.Setenv(name, value)
+
+			if _, err := New(); !errors.Is(err, ErrNotPositiveDuration) {
+				t.Errorf("%s=%s: err = %v, want %v", name, value, err, ErrNotPositiveDuration)
+			}
+		}
+
+		t.Setenv(name, "1m")
+
+		if _, err := New(); err != nil {
+			t.Errorf("%s=1m: %v", name, err)
+		}
+	}
+}
+
+func TestNewRejectsNegativeLeeway(t *testing.T) {
+	t.Setenv(envJwtSigningKey, "secret")
+	t.Setenv(envJwtLeeway, "-1s")
+
+	if _, err := New(); !errors.Is(err, ErrNegativeDuration) {
+		t.Errorf("err = %v, want %v", err, ErrNegativeDuration)
+	}
+
+	t.Setenv(envJwtLeeway, "0s")
+
+	config, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.JwtLeeway != 0 {
+		t.Errorf("JwtLeeway = %s, want 0s", config.JwtLeeway)
+	}
+}
+
+func TestNewRejectsBadDurations(t *testing.T) {
+	t.Setenv(envJwtSigningKey, "secret")
+	t.Setenv(envJwtTTL, "15")
+	t.Setenv(envBannerCacheTTL, "five minutes")
+
+	_, err := New()
+	if !errors.Is(err, ErrBadDuration) {
+		t.Fatalf("err = %v, want %v", err, ErrBadDuration)
+	}
+
+	// every malformed duration is reported at once
+	for _, name := range []string{envJwtTTL, envBannerCacheTTL} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("err = %v, want it to name %s", err, name)
+		}
+	}
+
+	t.Setenv(envJwtTTL, "15m")
+	t.Setenv(envBannerCacheTTL, "5m")
+
+	config, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.JwtTTL != 15*time.Minute || config.BannerCacheTTL != 5*time.Minute {
+		t.Errorf("JwtTTL = %s, BannerCacheTTL = %s, want 15m, 5m", config.JwtTTL, config.BannerCacheTTL)
+	}
+}
@@ -1,104 +1,195 @@
 package jwt
 
 import (
+	"errors"
 	"fmt"
+	"github.com/SanExpett/banners-backend/pkg/models"
 	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
 	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/SanExpett/banners-backend/pkg/utils"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
+	"strconv"
+	"time"
 )
 
-var Secret = []byte("super-secret")
+const (
+	tokenIDLen = 16
+)
 
 var (
-	ErrNilToken           = myerrors.NewError("Получили токен = nil")
-	ErrWrongSigningMethod = myerrors.NewError("Неожиданный signing метод ")
-	ErrInvalidToken       = myerrors.NewError("Некорректный токен")
+	ErrNilToken = myerrors.NewError("nil_token", myerrors.Message{
+		RU: "Получили токен = nil",
+		EN: "Got token = nil",
+	})
+	ErrInvalidToken = myerrors.NewUnauthorizedError("invalid_token", myerrors.Message{
+		RU: "Некорректный токен",
+		EN: "Invalid token",
+	})
+	ErrTokenExpired = myerrors.NewUnauthorizedError("token_expired", myerrors.Message{
+		RU: "Срок действия токена истек",
+		EN: "Token has expired",
+	})
+	ErrTokenRevoked = myerrors.NewUnauthorizedError("token_revoked", myerrors.Message{
+		RU: "Токен отозван, войдите заново",
+		EN: "Token has been revoked, sign in again",
+	})
 )
 
+// UserJwtPayload is user of token. ID is jti of token and SessionID is id of sign in,
+// which token is issued for, they are set with ExpiresAt when token is generated.
+// Grants are permissions of user on banners.
 type UserJwtPayload struct {
-	UserID  uint64
-	Expire  int64
-	Login   string
-	IsAdmin bool
+	UserID    uint64
+	Login     string
+	IsAdmin   bool
+	Grants    []models.Grant
+	ID        string
+	SessionID string
+	ExpiresAt time.Time
 }
 
-func NewUserJwtPayload(rawJwt string, secret []byte) (*UserJwtPayload, error) {
+// userClaims are claims of user token, user id is kept in sub.
+type userClaims struct {
+	Login     string         `json:"login"`
+	IsAdmin   bool           `json:"is_admin"`
+	Grants    []models.Grant `json:"grants,omitempty"`
+	SessionID string         `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Manager issues and verifies user tokens with keys of keySet. Tokens are issued by issuer
+// for audience and live for ttl, leeway is allowed clock skew when exp, nbf and iat are checked.
+type Manager struct {
+	keySet   *KeySet
+	issuer   string
+	audience string
+	ttl      time.Duration
+	leeway   time.Duration
+	logger   *zap.SugaredLogger
+}
+
+func NewManager(keySet *KeySet, issuer string, audience string, ttl time.Duration,
+	leeway time.Duration) (*Manager, error) {
 	logger, err := my_logger.Get()
 	if err != nil {
 		return nil, fmt.Errorf(myerrors.ErrTemplate, err)
 	}
 
-	tokenDuplicity, err := jwt.Parse(rawJwt, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			logger.Errorf("method == %+v %w", token.Header["alg"], ErrWrongSigningMethod)
+	return &Manager{
+		keySet:   keySet,
+		issuer:   issuer,
+		audience: audience,
+		ttl:      ttl,
+		leeway:   leeway,
+		logger:   logger,
+	}, nil
+}
 
-			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
-		}
+// TTL is lifetime of issued tokens.
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
 
-		return secret, nil
-	})
-	if err != nil {
-		logger.Errorf("%s", err.Error())
+// GenerateJwtToken signs token for userToken and sets its ID and ExpiresAt.
+func (m *Manager) GenerateJwtToken(userToken *UserJwtPayload) (string, error) {
+	if userToken == nil {
+		m.logger.Errorln(ErrNilToken)
 
-		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
+		return "", fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
 	}
 
-	if claims, ok := tokenDuplicity.Claims.(jwt.MapClaims); ok && tokenDuplicity.Valid {
-		interfaceUserID, ok1 := claims["userID"]
-		interfaceExpire, ok2 := claims["expire"]
-		interfaceLogin, ok3 := claims["login"]
-		interfaceIsAdmin, ok4 := claims["is_admin"]
+	tokenID, err := utils.RandomString(tokenIDLen)
+	if err != nil {
+		m.logger.Errorln(err)
 
-		if !(ok1 && ok2 && ok3 && ok4) {
-			logger.Errorf("error with claims: %+v", claims)
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
 
-			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
-		}
+	now := time.Now()
+
+	userToken.ID = tokenID
+	userToken.ExpiresAt = now.Add(m.ttl)
+
+	claims := &userClaims{
+		Login:     userToken.Login,
+		IsAdmin:   userToken.IsAdmin,
+		Grants:    userToken.Grants,
+		SessionID: userToken.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{ //nolint:exhaustruct
+			ID:        tokenID,
+			Issuer:    m.issuer,
+			Subject:   strconv.FormatUint(userToken.UserID, 10),
+			Audience:  jwt.ClaimStrings{m.audience},
+			ExpiresAt: jwt.NewNumericDate(userToken.ExpiresAt),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
 
-		userID, ok1 := interfaceUserID.(float64)
-		expire, ok2 := interfaceExpire.(float64)
-		login, ok3 := interfaceLogin.(string)
-		isAdmin, ok4 := interfaceIsAdmin.(bool)
+	signing := m.keySet.signing
 
-		if !(ok1 && ok2 && ok3 && ok4) {
-			logger.Errorf("error with casting claims: %+v", claims)
+	token := jwt.NewWithClaims(signing.method(), claims)
+	token.Header["kid"] = signing.ID
 
-			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
-		}
+	tokenString, err := token.SignedString(signing.signKey)
+	if err != nil {
+		m.logger.Errorln(err)
 
-		return &UserJwtPayload{UserID: uint64(userID), Expire: int64(expire), Login: login, IsAdmin: isAdmin}, nil
+		return "", fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
 	}
 
-	return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
+	return tokenString, nil
 }
 
-func (u *UserJwtPayload) getMapClaims() jwt.MapClaims {
-	result := make(jwt.MapClaims)
-
-	result["userID"] = u.UserID
-	result["expire"] = u.Expire
-	result["login"] = u.Login
-	result["is_admin"] = u.IsAdmin
-
-	return result
+// JWKS returns public keys which verify tokens of m.
+func (m *Manager) JWKS() *JWKS {
+	return m.keySet.JWKS()
 }
 
-func GenerateJwtToken(userToken *UserJwtPayload, secret []byte, logger *zap.SugaredLogger) (string, error) {
-	if userToken == nil {
-		logger.Errorln(ErrNilToken)
+// NewUserJwtPayload verifies signature and registered claims of rawJwt and returns its user.
+func (m *Manager) NewUserJwtPayload(rawJwt string) (*UserJwtPayload, error) {
+	claims := &userClaims{} //nolint:exhaustruct
+
+	_, err := jwt.ParseWithClaims(rawJwt, claims, m.keySet.keyFunc,
+		jwt.WithValidMethods(m.keySet.algorithms()),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(m.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(m.leeway),
+	)
+	if err != nil {
+		m.logger.Errorf("in NewUserJwtPayload: %s", err.Error())
 
-		return "", fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
-	}
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, fmt.Errorf(myerrors.ErrTemplate, ErrTokenExpired)
+		}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, userToken.getMapClaims())
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
+	}
 
-	tokenString, err := token.SignedString(secret)
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
 	if err != nil {
-		logger.Errorln(err)
+		m.logger.Errorf("in NewUserJwtPayload: wrong sub claim %q", claims.Subject)
 
-		return "", fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
 	}
 
-	return tokenString, nil
+	// token without jti could not be revoked
+	if claims.ID == "" {
+		m.logger.Errorf("in NewUserJwtPayload: no jti claim")
+
+		return nil, fmt.Errorf(myerrors.ErrTemplate, ErrInvalidToken)
+	}
+
+	return &UserJwtPayload{
+		UserID:    userID,
+		Login:     claims.Login,
+		IsAdmin:   claims.IsAdmin,
+		Grants:    claims.Grants,
+		ID:        claims.ID,
+		SessionID: claims.SessionID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
 }
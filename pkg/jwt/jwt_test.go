@@ -0,0 +1,243 @@
+package jwt
+
+import (
+	"errors"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "issuer"
+	testAudience = "audience"
+)
+
+var testSecret = []byte("test-secret") //nolint:gochecknoglobals
+
+func TestMain(m *testing.M) {
+	if _, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// newHSManager returns manager signing tokens by testSecret with HS256.
+func newHSManager(t *testing.T, leeway time.Duration) *Manager {
+	t.Helper()
+
+	keySet, err := NewKeySet(mustSigningKey(t, "hs", AlgHS256, testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := NewManager(keySet, testIssuer, testAudience, time.Hour, leeway)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return manager
+}
+
+// signTestClaims signs claims as they are, so test can build tokens manager would not issue.
+func signTestClaims(t *testing.T, method jwt.SigningMethod, key any, claims jwt.Claims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func validTestClaims() *userClaims {
+	now := time.Now()
+
+	return &userClaims{
+		Login:   "login",
+		IsAdmin: true,
+		RegisteredClaims: jwt.RegisteredClaims{ //nolint:exhaustruct
+			ID:        "jti",
+			Issuer:    testIssuer,
+			Subject:   "7",
+			Audience:  jwt.ClaimStrings{testAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+}
+
+func TestManagerRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	manager := newHSManager(t, 0)
+
+	issued := &UserJwtPayload{ //nolint:exhaustruct
+		UserID:    7,
+		Login:     "login",
+		IsAdmin:   true,
+		Grants:    []models.Grant{{Permission: models.PermBannerRead, FeatureID: 3}},
+		SessionID: "sid",
+	}
+
+	token, err := manager.GenerateJwtToken(issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if issued.ID == "" || issued.ExpiresAt.IsZero() {
+		t.Errorf("jti and expiry are not set to issued payload: %+v", issued)
+	}
+
+	payload, err := manager.NewUserJwtPayload(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.UserID != 7 || payload.Login != "login" || !payload.IsAdmin || payload.SessionID != "sid" ||
+		payload.ID != issued.ID || !slices.Equal(payload.Grants, issued.Grants) ||
+		!payload.ExpiresAt.Equal(issued.ExpiresAt.Truncate(time.Second)) {
+		t.Errorf("payload = %+v, want %+v", payload, issued)
+	}
+
+	if another, _ := manager.GenerateJwtToken(&UserJwtPayload{UserID: 7}); another == token { //nolint:exhaustruct
+		t.Error("tokens of the same user are equal, jti is not unique")
+	}
+
+	if _, err := manager.GenerateJwtToken(nil); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err for nil payload = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestManagerRejectsTokens(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		leeway  time.Duration
+		token   func(t *testing.T) string
+		wantErr error
+	}{
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				claims := validTestClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+				return signTestClaims(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name: "without exp",
+			token: func(t *testing.T) string {
+				claims := validTestClaims()
+				claims.ExpiresAt = nil
+
+				return signTestClaims(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "without jti",
+			token: func(t *testing.T) string {
+				claims := validTestClaims()
+				claims.ID = ""
+
+				return signTestClaims(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "other issuer",
+			token: func(t *testing.T) string {
+				claims := validTestClaims()
+				claims.Issuer = "other"
+
+				return signTestClaims(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "other audience",
+			token: func(t *testing.T) string {
+				claims := validTestClaims()
+				claims.Audience = jwt.ClaimStrings{"other"}
+
+				return signTestClaims(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "not yet valid",
+			token: func(t *testing.T) string {
+				claims := validTestClaims()
+				claims.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+				return signTestClaims(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "subject is not user id",
+			token: func(t *testing.T) string {
+				claims := validTestClaims()
+				claims.Subject = "admin"
+
+				return signTestClaims(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "other secret",
+			token: func(t *testing.T) string {
+				return signTestClaims(t, jwt.SigningMethodHS256, []byte("other"), validTestClaims())
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "other algorithm",
+			token: func(t *testing.T) string {
+				return signTestClaims(t, jwt.SigningMethodHS512, testSecret, validTestClaims())
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "alg none",
+			token: func(t *testing.T) string {
+				return signTestClaims(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, validTestClaims())
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:   "expired within leeway",
+			leeway: time.Minute,
+			token: func(t *testing.T) string {
+				claims := validTestClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second))
+
+				return signTestClaims(t, jwt.SigningMethodHS256, testSecret, claims)
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newHSManager(t, tt.leeway).NewUserJwtPayload(tt.token(t))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+	"strings"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnsupportedKeyAlg = errors.New("unsupported algorithm of jwt key")
+	ErrEmptyKey          = errors.New("empty jwt key")
+	ErrBadVerifyKeys     = errors.New("jwt verify keys must be comma separated kid:alg:path")
+	ErrDuplicateKeyID    = errors.New("kid of jwt keys must be unique")
+)
+
+// Key is a key of keyset. signKey is nil for keys which only verify tokens.
+type Key struct {
+	ID        string
+	Algorithm string
+	signKey   any
+	verifyKey any
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// NewSigningKey parses key which signs tokens: secret for HS256 or PEM of private key
+// for RS256 and EdDSA.
+func NewSigningKey(id string, alg string, material []byte) (*Key, error) {
+	if len(material) == 0 {
+		return nil, fmt.Errorf("%w: kid=%s", ErrEmptyKey, id)
+	}
+
+	var (
+		signKey   crypto.PrivateKey
+		verifyKey crypto.PublicKey
+		err       error
+	)
+
+	switch alg {
+	case AlgHS256:
+		signKey, verifyKey = material, material
+	case AlgRS256:
+		var privateKey *rsa.PrivateKey
+
+		privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(material)
+		if err == nil {
+			signKey, verifyKey = privateKey, &privateKey.PublicKey
+		}
+	case AlgEdDSA:
+		signKey, err = jwt.ParseEdPrivateKeyFromPEM(material)
+		if err == nil {
+			verifyKey = signKey.(ed25519.PrivateKey).Public() //nolint:forcetypeassert
+		}
+	default:
+		return nil, fmt.Errorf("%w: kid=%s alg=%s", ErrUnsupportedKeyAlg, id, alg)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("kid=%s: %w", id, err)
+	}
+
+	return &Key{ID: id, Algorithm: alg, signKey: signKey, verifyKey: verifyKey}, nil
+}
+
+// NewVerifyKey parses key which only verifies tokens: secret for HS256 or PEM
+// of public key for RS256 and EdDSA.
+func NewVerifyKey(id string, alg string, material []byte) (*Key, error) {
+	if len(material) == 0 {
+		return nil, fmt.Errorf("%w: kid=%s", ErrEmptyKey, id)
+	}
+
+	var (
+		verifyKey crypto.PublicKey
+		err       error
+	)
+
+	switch alg {
+	case AlgHS256:
+		verifyKey = material
+	case AlgRS256:
+		verifyKey, err = jwt.ParseRSAPublicKeyFromPEM(material)
+	case AlgEdDSA:
+		verifyKey, err = jwt.ParseEdPublicKeyFromPEM(material)
+	default:
+		return nil, fmt.Errorf("%w: kid=%s alg=%s", ErrUnsupportedKeyAlg, id, alg)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("kid=%s: %w", id, err)
+	}
+
+	return &Key{ID: id, Algorithm: alg, signKey: nil, verifyKey: verifyKey}, nil
+}
+
+// LoadSigningKey returns signing key read from file at path, or given as key if path is empty.
+func LoadSigningKey(id string, alg string, key string, path string) (*Key, error) {
+	material := []byte(key)
+
+	if path != "" {
+		var err error
+
+		material, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("kid=%s: %w", id, err)
+		}
+	}
+
+	return NewSigningKey(id, alg, material)
+}
+
+// LoadVerifyKeys returns keys described by spec as comma separated kid:alg:path.
+func LoadVerifyKeys(spec string) ([]*Key, error) {
+	var keys []*Key
+
+	for _, keySpec := range strings.Split(spec, ",") {
+		keySpec = strings.TrimSpace(keySpec)
+		if keySpec == "" {
+			continue
+		}
+
+		parts := strings.SplitN(keySpec, ":", 3) //nolint:gomnd
+		if len(parts) != 3 || parts[0] == "" {   //nolint:gomnd
+			return nil, fmt.Errorf("%w: %s", ErrBadVerifyKeys, keySpec)
+		}
+
+		material, err := os.ReadFile(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("kid=%s: %w", parts[0], err)
+		}
+
+		key, err := NewVerifyKey(parts[0], parts[1], material)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// KeySet signs new tokens with its signing key and verifies tokens with any of its keys,
+// so keys can be rotated without invalidating already issued tokens.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	// ordered keeps keys in order they were given for JWKS
+	ordered []*Key
+}
+
+func NewKeySet(signing *Key, verifyOnly ...*Key) (*KeySet, error) {
+	keySet := &KeySet{signing: signing, keys: make(map[string]*Key), ordered: nil}
+
+	for _, key := range append([]*Key{signing}, verifyOnly...) {
+		if _, ok := keySet.keys[key.ID]; ok {
+			return nil, fmt.Errorf("%w: kid=%s", ErrDuplicateKeyID, key.ID)
+		}
+
+		keySet.keys[key.ID] = key
+		keySet.ordered = append(keySet.ordered, key)
+	}
+
+	return keySet, nil
+}
+
+// algorithms returns algorithms of all keys of set.
+func (s *KeySet) algorithms() []string {
+	algorithms := make([]string, 0, len(s.ordered))
+
+	for _, key := range s.ordered {
+		algorithms = append(algorithms, key.Algorithm)
+	}
+
+	return algorithms
+}
+
+// keyFunc finds key of token by its kid, tokens without kid are verified by signing key.
+// Algorithm of token must be the one of key.
+func (s *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := s.signing
+
+	if kid, ok := token.Header["kid"]; ok {
+		kidStr, ok := kid.(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		key, ok = s.keys[kidStr]
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, ErrInvalidToken
+	}
+
+	return key.verifyKey, nil
+}
+
+// JWK is public key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns public keys of set, HMAC secrets are never published.
+func (s *KeySet) JWKS() *JWKS {
+	jwks := &JWKS{Keys: []JWK{}}
+
+	for _, key := range s.ordered {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Algorithm} //nolint:exhaustruct
+
+		switch publicKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
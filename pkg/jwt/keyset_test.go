@@ -0,0 +1,385 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testRSABits = 2048
+
+func pemOf(t *testing.T, blockType string, der []byte, err error) []byte {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+// newRSAPEM returns PEM of new private RSA key and of its public key.
+func newRSAPEM(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, testRSABits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	privatePEM := pemOf(t, "PRIVATE KEY", privateDER, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+
+	return privatePEM, pemOf(t, "PUBLIC KEY", publicDER, err)
+}
+
+// newEdPEM returns PEM of new private Ed25519 key and of its public key.
+func newEdPEM(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	privatePEM := pemOf(t, "PRIVATE KEY", privateDER, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+
+	return privatePEM, pemOf(t, "PUBLIC KEY", publicDER, err)
+}
+
+func mustSigningKey(t *testing.T, id string, alg string, material []byte) *Key {
+	t.Helper()
+
+	key, err := NewSigningKey(id, alg, material)
+	if err != nil {
+		t.Fatalf("NewSigningKey(%s, %s): %v", id, alg, err)
+	}
+
+	return key
+}
+
+func newTestManager(t *testing.T, signing *Key, verifyOnly ...*Key) *Manager {
+	t.Helper()
+
+	keySet, err := NewKeySet(signing, verifyOnly...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := NewManager(keySet, testIssuer, testAudience, time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return manager
+}
+
+func generateToken(t *testing.T, manager *Manager) string {
+	t.Helper()
+
+	token, err := manager.GenerateJwtToken(&UserJwtPayload{UserID: 7, Login: "user"}) //nolint:exhaustruct
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestNewSigningKey(t *testing.T) {
+	t.Parallel()
+
+	rsaPrivate, rsaPublic := newRSAPEM(t)
+	edPrivate, _ := newEdPEM(t)
+
+	tests := []struct {
+		name     string
+		alg      string
+		material []byte
+		wantErr  error
+		wantAny  bool
+	}{
+		{name: "hmac secret", alg: AlgHS256, material: []byte("secret")},
+		{name: "rsa private key", alg: AlgRS256, material: rsaPrivate},
+		{name: "ed25519 private key", alg: AlgEdDSA, material: edPrivate},
+		{name: "empty key", alg: AlgHS256, material: nil, wantErr: ErrEmptyKey},
+		{name: "unsupported algorithm", alg: "HS512", material: []byte("secret"), wantErr: ErrUnsupportedKeyAlg},
+		{name: "public key can not sign", alg: AlgRS256, material: rsaPublic, wantAny: true},
+		{name: "key of other algorithm", alg: AlgEdDSA, material: rsaPrivate, wantAny: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := NewSigningKey("kid", tt.alg, tt.material)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantAny:
+				if err == nil {
+					t.Fatal("err = nil, want error")
+				}
+			default:
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+
+				if key.ID != "kid" || key.Algorithm != tt.alg || key.signKey == nil || key.verifyKey == nil {
+					t.Fatalf("key = %+v", key)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadVerifyKeys(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	_, rsaPublic := newRSAPEM(t)
+	_, edPublic := newEdPEM(t)
+
+	files := map[string][]byte{"hs.key": []byte("old-secret"), "rsa.pem": rsaPublic, "ed.pem": edPublic}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := LoadVerifyKeys(" old:HS256:" + filepath.Join(dir, "hs.key") +
+		", rsa:RS256:" + filepath.Join(dir, "rsa.pem") + ",,ed:EdDSA:" + filepath.Join(dir, "ed.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantKeys := [][2]string{{"old", AlgHS256}, {"rsa", AlgRS256}, {"ed", AlgEdDSA}}
+	if len(keys) != len(wantKeys) {
+		t.Fatalf("got %d keys, want %d", len(keys), len(wantKeys))
+	}
+
+	for i, key := range keys {
+		if key.ID != wantKeys[i][0] || key.Algorithm != wantKeys[i][1] || key.signKey != nil {
+			t.Errorf("key %d = %+v, want verify only key %v", i, key, wantKeys[i])
+		}
+	}
+
+	if keys, err := LoadVerifyKeys(""); err != nil || len(keys) != 0 {
+		t.Errorf("LoadVerifyKeys(\"\") = %v, %v, want no keys", keys, err)
+	}
+
+	for _, spec := range []string{"old", "old:HS256", ":HS256:" + filepath.Join(dir, "hs.key")} {
+		if _, err := LoadVerifyKeys(spec); !errors.Is(err, ErrBadVerifyKeys) {
+			t.Errorf("LoadVerifyKeys(%q) err = %v, want %v", spec, err, ErrBadVerifyKeys)
+		}
+	}
+
+	if _, err := LoadVerifyKeys("old:HS256:" + filepath.Join(dir, "missing.key")); err == nil {
+		t.Error("LoadVerifyKeys of missing file err = nil, want error")
+	}
+}
+
+func TestNewKeySetDuplicateKeyID(t *testing.T) {
+	t.Parallel()
+
+	signing := mustSigningKey(t, "same", AlgHS256, []byte("new-secret"))
+	verifyOnly := mustSigningKey(t, "same", AlgHS256, []byte("old-secret"))
+
+	if _, err := NewKeySet(signing, verifyOnly); !errors.Is(err, ErrDuplicateKeyID) {
+		t.Fatalf("err = %v, want %v", err, ErrDuplicateKeyID)
+	}
+}
+
+func TestManagerKeySelection(t *testing.T) {
+	t.Parallel()
+
+	rsaPrivate, _ := newRSAPEM(t)
+	edPrivate, _ := newEdPEM(t)
+
+	for _, key := range []*Key{
+		mustSigningKey(t, "hs", AlgHS256, []byte("secret")),
+		mustSigningKey(t, "rsa", AlgRS256, rsaPrivate),
+		mustSigningKey(t, "ed", AlgEdDSA, edPrivate),
+	} {
+		key := key
+
+		t.Run(key.Algorithm, func(t *testing.T) {
+			t.Parallel()
+
+			manager := newTestManager(t, key)
+			rawToken := generateToken(t, manager)
+
+			token, _, err := jwt.NewParser().ParseUnverified(rawToken, &userClaims{}) //nolint:exhaustruct
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if token.Header["kid"] != key.ID || token.Method.Alg() != key.Algorithm {
+				t.Fatalf("header = %v, want kid %s and alg %s", token.Header, key.ID, key.Algorithm)
+			}
+
+			payload, err := manager.NewUserJwtPayload(rawToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if payload.UserID != 7 || payload.Login != "user" || payload.ID == "" {
+				t.Fatalf("payload = %+v", payload)
+			}
+		})
+	}
+}
+
+func TestManagerRotation(t *testing.T) {
+	t.Parallel()
+
+	edPrivate, edPublic := newEdPEM(t)
+
+	oldSigning := mustSigningKey(t, "old", AlgEdDSA, edPrivate)
+	oldToken := generateToken(t, newTestManager(t, oldSigning))
+
+	oldVerifyOnly, err := NewVerifyKey("old", AlgEdDSA, edPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newSigning := mustSigningKey(t, "new", AlgHS256, []byte("new-secret"))
+
+	rotated := newTestManager(t, newSigning, oldVerifyOnly)
+
+	if _, err := rotated.NewUserJwtPayload(oldToken); err != nil {
+		t.Errorf("token of previous key is rejected after rotation: %v", err)
+	}
+
+	if _, err := rotated.NewUserJwtPayload(generateToken(t, rotated)); err != nil {
+		t.Errorf("token of new key is rejected: %v", err)
+	}
+
+	if _, err := newTestManager(t, newSigning).NewUserJwtPayload(oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token of removed key err = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestManagerRejectsForeignTokens(t *testing.T) {
+	t.Parallel()
+
+	rsaPrivate, _ := newRSAPEM(t)
+
+	hsKey := mustSigningKey(t, "hs", AlgHS256, []byte("secret"))
+	rsaKey := mustSigningKey(t, "rsa", AlgRS256, rsaPrivate)
+	manager := newTestManager(t, hsKey, rsaKey)
+
+	sign := func(method jwt.SigningMethod, kid any, signKey any) string {
+		claims := &userClaims{ //nolint:exhaustruct
+			RegisteredClaims: jwt.RegisteredClaims{ //nolint:exhaustruct
+				ID:        "jti",
+				Issuer:    testIssuer,
+				Subject:   "7",
+				Audience:  jwt.ClaimStrings{testAudience},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+			},
+		}
+
+		token := jwt.NewWithClaims(method, claims)
+		if kid != nil {
+			token.Header["kid"] = kid
+		}
+
+		rawToken, err := token.SignedString(signKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return rawToken
+	}
+
+	if _, err := manager.NewUserJwtPayload(sign(jwt.SigningMethodHS256, nil, hsKey.signKey)); err != nil {
+		t.Errorf("token without kid is not verified by signing key: %v", err)
+	}
+
+	tests := map[string]string{
+		"unknown kid":            sign(jwt.SigningMethodHS256, "unknown", hsKey.signKey),
+		"kid is not string":      sign(jwt.SigningMethodHS256, 1, hsKey.signKey),
+		"alg differs from key":   sign(jwt.SigningMethodRS256, "hs", rsaKey.signKey),
+		"hmac with rsa key":      sign(jwt.SigningMethodHS256, "rsa", hsKey.signKey),
+		"signature of other key": sign(jwt.SigningMethodHS256, "hs", []byte("other-secret")),
+	}
+
+	for name, rawToken := range tests {
+		if _, err := manager.NewUserJwtPayload(rawToken); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want %v", name, err, ErrInvalidToken)
+		}
+	}
+}
+
+func TestKeySetJWKS(t *testing.T) {
+	t.Parallel()
+
+	rsaPrivate, _ := newRSAPEM(t)
+	edPrivate, _ := newEdPEM(t)
+
+	rsaKey := mustSigningKey(t, "rsa", AlgRS256, rsaPrivate)
+	edKey := mustSigningKey(t, "ed", AlgEdDSA, edPrivate)
+
+	keySet, err := NewKeySet(mustSigningKey(t, "hs", AlgHS256, []byte("secret")), rsaKey, edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := keySet.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2 without hmac secret: %+v", len(jwks.Keys), jwks.Keys)
+	}
+
+	rsaJWK, edJWK := jwks.Keys[0], jwks.Keys[1]
+
+	rsaPublic := rsaKey.verifyKey.(*rsa.PublicKey) //nolint:forcetypeassert
+
+	n, err := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rsaJWK.Kty != "RSA" || rsaJWK.Kid != "rsa" || rsaJWK.Alg != AlgRS256 || rsaJWK.Use != "sig" ||
+		new(big.Int).SetBytes(n).Cmp(rsaPublic.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(rsaPublic.E) {
+		t.Errorf("rsa jwk = %+v", rsaJWK)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(edJWK.X)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPublic := edKey.verifyKey.(ed25519.PublicKey) //nolint:forcetypeassert
+
+	if edJWK.Kty != "OKP" || edJWK.Crv != "Ed25519" || edJWK.Kid != "ed" || edJWK.Alg != AlgEdDSA ||
+		!edPublic.Equal(ed25519.PublicKey(x)) {
+		t.Errorf("ed25519 jwk = %+v", edJWK)
+	}
+}
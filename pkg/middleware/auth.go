@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	"github.com/SanExpett/banners-backend/pkg/jwt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// IRevokedTokens tells if access token was revoked on log out before it expired.
+// It is checked on every request, so it must not go to storage.
+type IRevokedTokens interface {
+	IsAccessTokenRevoked(jti string) bool
+}
+
+// Auth verifies token from Authorization header once and puts principal of its user
+// into context of request. Requests without valid or with revoked token are answered with 401.
+func Auth(next http.HandlerFunc, tokens *jwt.Manager, revokedTokens IRevokedTokens,
+	logger *zap.SugaredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			delivery.HandleErr(w, r, logger, delivery.ErrAuthHeaderNotPresented)
+
+			return
+		}
+
+		userPayload, err := tokens.NewUserJwtPayload(strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			delivery.HandleErr(w, r, logger, err)
+
+			return
+		}
+
+		if revokedTokens.IsAccessTokenRevoked(userPayload.ID) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			delivery.HandleErr(w, r, logger, fmt.Errorf(myerrors.ErrTemplate, jwt.ErrTokenRevoked))
+
+			return
+		}
+
+		principal := &models.Principal{
+			UserID:    userPayload.UserID,
+			Login:     userPayload.Login,
+			Roles:     []string{models.RoleUser},
+			Grants:    userPayload.Grants,
+			TokenID:   userPayload.ID,
+			SessionID: userPayload.SessionID,
+		}
+
+		if userPayload.IsAdmin {
+			principal.Roles = append(principal.Roles, models.RoleAdmin)
+		}
+
+		next(w, r.WithContext(delivery.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+// RequireAdmin lets through only requests of admins, others are answered with 403.
+// It must be wrapped by Auth.
+func RequireAdmin(next http.HandlerFunc, logger *zap.SugaredLogger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := delivery.GetPrincipal(r)
+		if err != nil {
+			delivery.HandleErr(w, r, logger, err)
+
+			return
+		}
+
+		if !principal.IsAdmin() {
+			delivery.HandleErr(w, r, logger, delivery.ErrNotAdmin)
+
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"github.com/SanExpett/banners-backend/internal/server/delivery"
+	"github.com/SanExpett/banners-backend/pkg/jwt"
+	"github.com/SanExpett/banners-backend/pkg/models"
+	"github.com/SanExpett/banners-backend/pkg/my_logger"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var testSecret = []byte("test-secret") //nolint:gochecknoglobals
+
+func TestMain(m *testing.M) {
+	if _, err := my_logger.New([]string{os.DevNull}, []string{os.DevNull}); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// fakeRevokedTokens denylists the only jti.
+type fakeRevokedTokens struct {
+	jti string
+}
+
+func (f *fakeRevokedTokens) IsAccessTokenRevoked(jti string) bool {
+	return jti == f.jti
+}
+
+func newTestTokens(t *testing.T, secret []byte) *jwt.Manager {
+	t.Helper()
+
+	signing, err := jwt.NewSigningKey("hs", jwt.AlgHS256, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := jwt.NewKeySet(signing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := jwt.NewManager(keySet, "issuer", "audience", time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokens
+}
+
+func newTestToken(t *testing.T, isAdmin bool, secret []byte) string {
+	t.Helper()
+
+	token, err := newTestTokens(t, secret).GenerateJwtToken(&jwt.UserJwtPayload{ //nolint:exhaustruct
+		UserID:    7,
+		Login:     "login",
+		IsAdmin:   isAdmin,
+		SessionID: "sid",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+// principalHandler remembers principal of request it is called with.
+func principalHandler(got **models.Principal) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := delivery.GetPrincipal(r)
+		if err != nil {
+			panic(err)
+		}
+
+		*got = principal
+	}
+}
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+
+	tokens := newTestTokens(t, testSecret)
+
+	tests := []struct {
+		name          string
+		authHeader    string
+		wantStatus    int
+		wantChallenge string
+		wantRoles     []string
+	}{
+		{
+			name: "no header", authHeader: "",
+			wantStatus: http.StatusUnauthorized, wantChallenge: "Bearer",
+		},
+		{
+			name: "garbage token", authHeader: "Bearer abc",
+			wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer error="invalid_token"`,
+		},
+		{
+			name: "token signed by other secret", authHeader: "Bearer " + newTestToken(t, true, []byte("other")),
+			wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer error="invalid_token"`,
+		},
+		{
+			name: "user", authHeader: "Bearer " + newTestToken(t, false, testSecret),
+			wantStatus: http.StatusOK, wantRoles: []string{models.RoleUser},
+		},
+		{
+			name: "admin", authHeader: "Bearer " + newTestToken(t, true, testSecret),
+			wantStatus: http.StatusOK, wantRoles: []string{models.RoleUser, models.RoleAdmin},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var principal *models.Principal
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authHeader != "" {
+				r.Header.Set("Authorization", tt.authHeader)
+			}
+
+			w := httptest.NewRecorder()
+			Auth(principalHandler(&principal), tokens, &fakeRevokedTokens{}, zap.NewNop().Sugar())(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantChallenge)
+			}
+
+			if tt.wantRoles == nil {
+				if principal != nil {
+					t.Error("handler is called without valid token")
+				}
+
+				return
+			}
+
+			if principal == nil || principal.UserID != 7 || principal.Login != "login" ||
+				principal.SessionID != "sid" || principal.TokenID == "" ||
+				!slices.Equal(principal.Roles, tt.wantRoles) {
+				t.Errorf("principal = %+v, want user 7 with roles %v", principal, tt.wantRoles)
+			}
+		})
+	}
+}
+
+func TestAuthRevokedToken(t *testing.T) {
+	t.Parallel()
+
+	tokens := newTestTokens(t, testSecret)
+	payload := &jwt.UserJwtPayload{UserID: 7, Login: "login"} //nolint:exhaustruct
+
+	token, err := tokens.GenerateJwtToken(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	called := false
+	next := func(http.ResponseWriter, *http.Request) { called = true }
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	Auth(next, tokens, &fakeRevokedTokens{jti: payload.ID}, zap.NewNop().Sugar())(w, r)
+
+	if w.Code != http.StatusUnauthorized || called {
+		t.Errorf("status = %d, handler called = %t, want %d", w.Code, called, http.StatusUnauthorized)
+	}
+
+	if got := w.Header().Get("WWW-Authenticate"); got != `Bearer error="invalid_token"` {
+		t.Errorf("WWW-Authenticate = %q", got)
+	}
+
+	if !strings.Contains(w.Body.String(), jwt.ErrTokenRevoked.Code()) {
+		t.Errorf("body = %s, want code %s", w.Body.String(), jwt.ErrTokenRevoked.Code())
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		principal  *models.Principal
+		wantStatus int
+	}{
+		{name: "not authenticated", principal: nil, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "user",
+			principal:  &models.Principal{UserID: 1, Login: "user", Roles: []string{models.RoleUser}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "admin",
+			principal: &models.Principal{
+				UserID: 2, Login: "admin", Roles: []string{models.RoleUser, models.RoleAdmin},
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			called := false
+			next := func(http.ResponseWriter, *http.Request) { called = true }
+
+			r := httptest.NewRequest(http.MethodDelete, "/", nil)
+			if tt.principal != nil {
+				r = r.WithContext(delivery.WithPrincipal(r.Context(), tt.principal))
+			}
+
+			w := httptest.NewRecorder()
+			RequireAdmin(next, zap.NewNop().Sugar())(w, r)
+
+			if w.Code != tt.wantStatus || called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("status = %d, handler called = %t, want %d", w.Code, called, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -6,7 +6,8 @@ func setupHeadersCORS(w http.ResponseWriter, allowOrigin string, schema string)
 	w.Header().Set("Access-Control-Allow-Origin", schema+allowOrigin)
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, PATCH")
 	w.Header().Set("Access-Control-Allow-Headers",
-		"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		"Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, If-Match")
+	w.Header().Set("Access-Control-Expose-Headers", "ETag")
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
 }
 
@@ -12,8 +12,7 @@ func Panic(next http.Handler, logger *zap.SugaredLogger) http.Handler {
 		defer func() {
 			if err := recover(); err != nil {
 				logger.Errorf("panic recovered: %+v\n", err)
-				delivery.SendErrResponse(w, logger,
-					delivery.NewErrResponse(delivery.StatusErrInternalServer, delivery.ErrInternalServer))
+				delivery.HandleErr(w, r, logger, delivery.ErrInternal)
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -1,48 +1,105 @@
 package models
 
 import (
-	"github.com/microcosm-cc/bluemonday"
-	"strings"
+	"encoding/json"
 	"time"
 )
 
-type Content struct {
-	Title string `json:"title"   valid:"required"`
-	Text  string `json:"text"    valid:"required"`
-	URL   string `json:"url"     valid:"required"`
-}
-
+// Banner.Content and PreBanner.Content hold a JSON object of arbitrary structure,
+// it is stored and returned verbatim.
 type Banner struct {
-	BannerID  uint64    `json:"banner_id"    valid:"required"`
-	TagIDs    []uint64  `json:"tag_ids"      valid:"required"`
-	FeatureID uint64    `json:"feature_id"   valid:"required"`
-	Content   Content   `json:"content"      valid:"required"`
-	IsActive  bool      `json:"is_active"    valid:"required"`
-	CreatedAt time.Time `json:"created_at"   valid:"required"`
-	UpdatedAt time.Time `json:"updated_at"   valid:"optional"`
+	BannerID  uint64          `json:"banner_id"    valid:"required"`
+	TagIDs    []uint64        `json:"tag_ids"      valid:"required"`
+	FeatureID uint64          `json:"feature_id"   valid:"required"`
+	AuthorID  uint64          `json:"author_id"    valid:"optional"`
+	Content   json.RawMessage `json:"content"      valid:"required" swaggertype:"object"`
+	IsActive  bool            `json:"is_active"    valid:"required"`
+	Revision  uint64          `json:"revision"     valid:"required"`
+	CreatedAt time.Time       `json:"created_at"   valid:"required"`
+	UpdatedAt time.Time       `json:"updated_at"   valid:"optional"`
 }
 
 type PreBanner struct {
-	TagIDs    []uint64 `json:"tag_ids"      valid:"required"`
-	FeatureID uint64   `json:"feature_id"   valid:"required"`
-	Content   Content  `json:"content"      valid:"required"`
-	IsActive  bool     `json:"is_active"    valid:"required"`
+	TagIDs    []uint64        `json:"tag_ids"      valid:"required"`
+	FeatureID uint64          `json:"feature_id"   valid:"required"`
+	Content   json.RawMessage `json:"content"      valid:"required" swaggertype:"object"`
+	IsActive  bool            `json:"is_active"    valid:"required"`
+}
+
+// BannerPatch is a partial update of banner, omitted fields keep their current values.
+// TagIDs, if set, replace the whole tag set of banner.
+type BannerPatch struct {
+	TagIDs    *[]uint64       `json:"tag_ids,omitempty"`
+	FeatureID *uint64         `json:"feature_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"   swaggertype:"object"`
+	IsActive  *bool           `json:"is_active,omitempty"`
+}
+
+// Apply returns banner state after patch.
+func (p *BannerPatch) Apply(banner *Banner) *PreBanner {
+	preBanner := &PreBanner{
+		TagIDs:    banner.TagIDs,
+		FeatureID: banner.FeatureID,
+		Content:   banner.Content,
+		IsActive:  banner.IsActive,
+	}
+
+	if p.TagIDs != nil {
+		preBanner.TagIDs = *p.TagIDs
+	}
+
+	if p.FeatureID != nil {
+		preBanner.FeatureID = *p.FeatureID
+	}
+
+	if p.Content != nil {
+		preBanner.Content = p.Content
+	}
+
+	if p.IsActive != nil {
+		preBanner.IsActive = *p.IsActive
+	}
+
+	return preBanner
 }
 
-func (b *PreBanner) Trim() {
-	b.Content.Title = strings.TrimSpace(b.Content.Title)
-	b.Content.URL = strings.TrimSpace(b.Content.URL)
-	b.Content.Text = strings.TrimSpace(b.Content.Text)
+// BannerConflict describes a (feature, tag) pair that is already taken by another banner.
+type BannerConflict struct {
+	BannerID  uint64 `json:"banner_id"`
+	FeatureID uint64 `json:"feature_id"`
+	TagID     uint64 `json:"tag_id"`
 }
 
-func (c *Content) Sanitize() {
-	sanitizer := bluemonday.UGCPolicy()
+// MissingReferences lists ids of features and tags which banner references, but which do not exist.
+type MissingReferences struct {
+	FeatureIDs []uint64 `json:"feature_ids,omitempty"`
+	TagIDs     []uint64 `json:"tag_ids,omitempty"`
+}
+
+// RevisionConflict is returned when banner was changed since the client has read it.
+type RevisionConflict struct {
+	BannerID        uint64 `json:"banner_id"`
+	CurrentRevision uint64 `json:"current_revision"`
+}
 
-	c.Title = sanitizer.Sanitize(c.Title)
-	c.Text = sanitizer.Sanitize(c.Text)
-	c.URL = sanitizer.Sanitize(c.URL)
+// BannerCacheStats describes state of banners cache, it is used to alert when cache falls behind.
+// RefreshInterval is set only for index mode and TTL only for ttl mode.
+type BannerCacheStats struct {
+	Mode            string    `json:"mode"`
+	Size            int       `json:"size"`
+	LastBuildAt     time.Time `json:"last_build_at"`
+	RefreshInterval string    `json:"refresh_interval,omitempty"`
+	TTL             string    `json:"ttl,omitempty"`
 }
 
-func (b *Banner) Sanitize() {
-	b.Content.Sanitize()
+// BannerVersion is a state of banner saved after its creation or update.
+type BannerVersion struct {
+	BannerID  uint64          `json:"banner_id"    valid:"required"`
+	Version   uint64          `json:"version"      valid:"required"`
+	AuthorID  uint64          `json:"author_id"    valid:"required"`
+	TagIDs    []uint64        `json:"tag_ids"      valid:"required"`
+	FeatureID uint64          `json:"feature_id"   valid:"required"`
+	Content   json.RawMessage `json:"content"      valid:"required" swaggertype:"object"`
+	IsActive  bool            `json:"is_active"    valid:"required"`
+	CreatedAt time.Time       `json:"created_at"   valid:"required"`
 }
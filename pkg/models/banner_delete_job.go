@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	BannerDeleteJobPending = "pending"
+	BannerDeleteJobRunning = "running"
+	BannerDeleteJobDone    = "done"
+	BannerDeleteJobFailed  = "failed"
+)
+
+// BannerDeleteJob is a background deletion of all banners of feature or of tag.
+// Exactly one of FeatureID and TagID is set.
+type BannerDeleteJob struct {
+	ID           uint64 `json:"id"              valid:"required"`
+	AuthorID     uint64 `json:"author_id"       valid:"required"`
+	FeatureID    uint64 `json:"feature_id,omitempty"`
+	TagID        uint64 `json:"tag_id,omitempty"`
+	Status       string `json:"status"          valid:"required"`
+	DeletedCount uint64 `json:"deleted_count"`
+	// Attempts is the number of failed attempts, Error is the error of the last one
+	Attempts  uint64    `json:"attempts"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"      valid:"required"`
+	UpdatedAt time.Time `json:"updated_at"      valid:"required"`
+}
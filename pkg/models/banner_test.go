@@ -0,0 +1,80 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestBannerPatchApply(t *testing.T) {
+	t.Parallel()
+
+	newTagIDs := []uint64{7, 8}
+	newFeatureID := uint64(9)
+	newIsActive := false
+	newContent := json.RawMessage(`{"title": "new"}`)
+
+	tests := []struct {
+		name  string
+		patch *BannerPatch
+		want  *PreBanner
+	}{
+		{
+			name:  "empty patch keeps banner",
+			patch: &BannerPatch{}, //nolint:exhaustruct
+			want: &PreBanner{
+				TagIDs: []uint64{1, 2}, FeatureID: 3, Content: json.RawMessage(`{"title": "old"}`), IsActive: true,
+			},
+		},
+		{
+			name:  "tags replace whole tag set",
+			patch: &BannerPatch{TagIDs: &newTagIDs}, //nolint:exhaustruct
+			want: &PreBanner{
+				TagIDs: newTagIDs, FeatureID: 3, Content: json.RawMessage(`{"title": "old"}`), IsActive: true,
+			},
+		},
+		{
+			name:  "is_active false is applied",
+			patch: &BannerPatch{IsActive: &newIsActive}, //nolint:exhaustruct
+			want: &PreBanner{
+				TagIDs: []uint64{1, 2}, FeatureID: 3, Content: json.RawMessage(`{"title": "old"}`), IsActive: false,
+			},
+		},
+		{
+			name: "all fields",
+			patch: &BannerPatch{
+				TagIDs: &newTagIDs, FeatureID: &newFeatureID, Content: newContent, IsActive: &newIsActive,
+			},
+			want: &PreBanner{TagIDs: newTagIDs, FeatureID: newFeatureID, Content: newContent, IsActive: false},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			banner := &Banner{ //nolint:exhaustruct
+				BannerID:  1,
+				TagIDs:    []uint64{1, 2},
+				FeatureID: 3,
+				Content:   json.RawMessage(`{"title": "old"}`),
+				IsActive:  true,
+				Revision:  4,
+			}
+
+			got := tt.patch.Apply(banner)
+
+			if !slices.Equal(got.TagIDs, tt.want.TagIDs) || got.FeatureID != tt.want.FeatureID ||
+				!bytes.Equal(got.Content, tt.want.Content) || got.IsActive != tt.want.IsActive {
+				t.Errorf("Apply() = %+v, want %+v", got, tt.want)
+			}
+
+			if banner.FeatureID != 3 || !banner.IsActive || !slices.Equal(banner.TagIDs, []uint64{1, 2}) {
+				t.Errorf("banner is changed by Apply: %+v", banner)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	MaxLenFeatureTitle = 150
+)
+
+type Feature struct {
+	ID        uint64    `json:"id"           valid:"required"`
+	Title     string    `json:"title"        valid:"required"`
+	CreatedAt time.Time `json:"created_at"   valid:"required"`
+}
+
+type PreFeature struct {
+	Title string `json:"title" valid:"required,runelength(1|150)"`
+}
+
+func (f *PreFeature) Trim() {
+	f.Title = strings.TrimSpace(f.Title)
+}
+
+// FeatureInUse is returned when feature can not be deleted, because banners still reference it.
+type FeatureInUse struct {
+	FeatureID    uint64 `json:"feature_id"`
+	BannersCount uint64 `json:"banners_count"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FeatureSchema is a version of JSON Schema that content of feature banners must satisfy.
+// The latest version of feature is the current one.
+type FeatureSchema struct {
+	FeatureID uint64          `json:"feature_id"   valid:"required"`
+	Version   uint64          `json:"version"      valid:"required"`
+	Schema    json.RawMessage `json:"schema"       valid:"required" swaggertype:"object"`
+	AuthorID  uint64          `json:"author_id"    valid:"required"`
+	CreatedAt time.Time       `json:"created_at"   valid:"required"`
+}
+
+type ContentValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type BannerSchemaViolation struct {
+	BannerID uint64                   `json:"banner_id"`
+	Errors   []ContentValidationError `json:"errors"`
+}
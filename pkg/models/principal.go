@@ -0,0 +1,44 @@
+package models
+
+import "slices"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Principal is the authenticated user on whose behalf request is made. TokenID is jti
+// of its access token and SessionID is id of sign in, which token is issued for.
+// Grants are permissions given to user by its role bindings.
+type Principal struct {
+	UserID    uint64
+	Login     string
+	Roles     []string
+	Grants    []Grant
+	TokenID   string
+	SessionID string
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p.HasRole(RoleAdmin)
+}
+
+// Can tells if principal has permission on banners of feature featureID. Admin can do anything.
+func (p *Principal) Can(permission Permission, featureID uint64) bool {
+	if p.IsAdmin() {
+		return true
+	}
+
+	return slices.ContainsFunc(p.Grants, func(grant Grant) bool {
+		return grant.Permission == permission && (grant.FeatureID == 0 || grant.FeatureID == featureID)
+	})
+}
+
+// CanEverywhere tells if principal has permission on banners of all features.
+func (p *Principal) CanEverywhere(permission Permission) bool {
+	return p.Can(permission, 0)
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPrincipalCan(t *testing.T) {
+	t.Parallel()
+
+	const featureID, otherFeatureID = 1, 2
+
+	tests := []struct {
+		name       string
+		principal  *Principal
+		permission Permission
+		featureID  uint64
+		want       bool
+	}{
+		{
+			name:       "admin can do anything",
+			principal:  &Principal{Roles: []string{RoleUser, RoleAdmin}},
+			permission: PermBannerDelete, featureID: featureID, want: true,
+		},
+		{
+			name:       "admin can do anything everywhere",
+			principal:  &Principal{Roles: []string{RoleUser, RoleAdmin}},
+			permission: PermBannerDelete, featureID: 0, want: true,
+		},
+		{
+			name:       "user without grants",
+			principal:  &Principal{Roles: []string{RoleUser}},
+			permission: PermBannerRead, featureID: featureID, want: false,
+		},
+		{
+			name: "grant on feature",
+			principal: &Principal{Roles: []string{RoleUser}, Grants: []Grant{
+				{Permission: PermBannerRead, FeatureID: featureID},
+			}},
+			permission: PermBannerRead, featureID: featureID, want: true,
+		},
+		{
+			name: "grant on other feature",
+			principal: &Principal{Roles: []string{RoleUser}, Grants: []Grant{
+				{Permission: PermBannerRead, FeatureID: otherFeatureID},
+			}},
+			permission: PermBannerRead, featureID: featureID, want: false,
+		},
+		{
+			name: "grant on feature is not grant everywhere",
+			principal: &Principal{Roles: []string{RoleUser}, Grants: []Grant{
+				{Permission: PermBannerRead, FeatureID: featureID},
+			}},
+			permission: PermBannerRead, featureID: 0, want: false,
+		},
+		{
+			name: "grant on all features",
+			principal: &Principal{Roles: []string{RoleUser}, Grants: []Grant{
+				{Permission: PermBannerWrite, FeatureID: 0},
+			}},
+			permission: PermBannerWrite, featureID: featureID, want: true,
+		},
+		{
+			name: "other permission",
+			principal: &Principal{Roles: []string{RoleUser}, Grants: []Grant{
+				{Permission: PermBannerRead, FeatureID: 0},
+			}},
+			permission: PermBannerWrite, featureID: featureID, want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.principal.Can(tt.permission, tt.featureID); got != tt.want {
+				t.Errorf("Can(%s, %d) = %t, want %t", tt.permission, tt.featureID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrincipalCanEverywhere(t *testing.T) {
+	t.Parallel()
+
+	principal := &Principal{Roles: []string{RoleUser}, Grants: []Grant{
+		{Permission: PermBannerRead, FeatureID: 0},
+		{Permission: PermBannerWrite, FeatureID: 1},
+	}}
+
+	if !principal.CanEverywhere(PermBannerRead) {
+		t.Errorf("CanEverywhere(%s) = false, want true", PermBannerRead)
+	}
+
+	if principal.CanEverywhere(PermBannerWrite) {
+		t.Errorf("CanEverywhere(%s) = true, want false", PermBannerWrite)
+	}
+}
+
+func TestGrantsOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		bindings []*RoleBinding
+		want     []Grant
+	}{
+		{
+			name:     "no bindings",
+			bindings: nil,
+			want:     nil,
+		},
+		{
+			name:     "role gives all its permissions on feature",
+			bindings: []*RoleBinding{{ID: 1, UserID: 1, Role: RoleEditor, FeatureID: 3}},
+			want: []Grant{
+				{Permission: PermBannerRead, FeatureID: 3},
+				{Permission: PermBannerWrite, FeatureID: 3},
+			},
+		},
+		{
+			name: "permissions of several roles are not duplicated",
+			bindings: []*RoleBinding{
+				{ID: 1, UserID: 1, Role: RoleViewer, FeatureID: 3},
+				{ID: 2, UserID: 1, Role: RoleManager, FeatureID: 3},
+			},
+			want: []Grant{
+				{Permission: PermBannerRead, FeatureID: 3},
+				{Permission: PermBannerWrite, FeatureID: 3},
+				{Permission: PermBannerDelete, FeatureID: 3},
+			},
+		},
+		{
+			name: "same permission on different features",
+			bindings: []*RoleBinding{
+				{ID: 1, UserID: 1, Role: RoleViewer, FeatureID: 3},
+				{ID: 2, UserID: 1, Role: RoleViewer, FeatureID: 0},
+			},
+			want: []Grant{
+				{Permission: PermBannerRead, FeatureID: 3},
+				{Permission: PermBannerRead, FeatureID: 0},
+			},
+		},
+		{
+			name:     "unknown role gives nothing",
+			bindings: []*RoleBinding{{ID: 1, UserID: 1, Role: "owner", FeatureID: 3}},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := GrantsOf(tt.bindings); !slices.Equal(got, tt.want) {
+				t.Errorf("GrantsOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
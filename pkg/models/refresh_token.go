@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// RefreshToken is a stored refresh token, the token itself is never stored, only its hash.
+// Tokens rotated from one sign in have the same FamilyID.
+type RefreshToken struct {
+	ID              uint64
+	UserID          uint64
+	FamilyID        string
+	TokenHash       string
+	AccessJti       string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+}
+
+// RevokedAccessToken is access token which was revoked before it expired.
+type RevokedAccessToken struct {
+	Jti       string    `json:"jti"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Tokens are issued to user on sign in and on refresh.
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is lifetime of access token in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
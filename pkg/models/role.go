@@ -0,0 +1,84 @@
+package models
+
+import "slices"
+
+// Permission is an action on banners, which is granted by roles.
+type Permission string
+
+const (
+	PermBannerRead   Permission = "banner:read"
+	PermBannerWrite  Permission = "banner:write"
+	PermBannerDelete Permission = "banner:delete"
+)
+
+const (
+	RoleViewer  = "viewer"
+	RoleEditor  = "editor"
+	RoleManager = "manager"
+)
+
+// rolePermissions are permissions of roles, which can be bound to users.
+var rolePermissions = map[string][]Permission{ //nolint:gochecknoglobals
+	RoleViewer:  {PermBannerRead},
+	RoleEditor:  {PermBannerRead, PermBannerWrite},
+	RoleManager: {PermBannerRead, PermBannerWrite, PermBannerDelete},
+}
+
+type Role struct {
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Roles returns all roles which can be bound to users.
+func Roles() []*Role {
+	roles := make([]*Role, 0, len(rolePermissions))
+
+	for _, name := range []string{RoleViewer, RoleEditor, RoleManager} {
+		roles = append(roles, &Role{Name: name, Permissions: rolePermissions[name]})
+	}
+
+	return roles
+}
+
+func IsBindableRole(role string) bool {
+	_, ok := rolePermissions[role]
+
+	return ok
+}
+
+// RoleBinding gives role to user on banners of feature FeatureID, or of all features if FeatureID is 0.
+type RoleBinding struct {
+	ID        uint64 `json:"id"         valid:"required"`
+	UserID    uint64 `json:"user_id"    valid:"required"`
+	Role      string `json:"role"       valid:"required"`
+	FeatureID uint64 `json:"feature_id"`
+}
+
+type PreRoleBinding struct {
+	UserID    uint64 `json:"user_id"    valid:"required"`
+	Role      string `json:"role"       valid:"required"`
+	FeatureID uint64 `json:"feature_id"`
+}
+
+// Grant allows Permission on banners of feature FeatureID, or of all features if FeatureID is 0.
+// Grants are carried in user tokens.
+type Grant struct {
+	Permission Permission `json:"p"`
+	FeatureID  uint64     `json:"f,omitempty"`
+}
+
+// GrantsOf returns grants of permissions of roles of bindings without duplicates.
+func GrantsOf(bindings []*RoleBinding) []Grant {
+	var grants []Grant
+
+	for _, binding := range bindings {
+		for _, permission := range rolePermissions[binding.Role] {
+			grant := Grant{Permission: permission, FeatureID: binding.FeatureID}
+			if !slices.Contains(grants, grant) {
+				grants = append(grants, grant)
+			}
+		}
+	}
+
+	return grants
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type Tag struct {
+	ID          uint64 `json:"id"             valid:"required"`
+	Title       string `json:"title"          valid:"required"`
+	Description string `json:"description"`
+	// BannersCount is how many banners have this tag
+	BannersCount uint64    `json:"banners_count"`
+	CreatedAt    time.Time `json:"created_at"     valid:"required"`
+}
+
+type PreTag struct {
+	Title       string `json:"title"       valid:"required,runelength(1|150)"`
+	Description string `json:"description" valid:"runelength(0|1000)"`
+}
+
+func (t *PreTag) Trim() {
+	t.Title = strings.TrimSpace(t.Title)
+	t.Description = strings.TrimSpace(t.Description)
+}
+
+// TagInUse is returned when tag can not be deleted, because banners still have it.
+type TagInUse struct {
+	TagID        uint64 `json:"tag_id"`
+	BannersCount uint64 `json:"banners_count"`
+}
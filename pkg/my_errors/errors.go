@@ -1,19 +1,141 @@
 package my_errors
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+)
 
 const (
 	ErrTemplate = "%w"
 )
 
+// Kind tells what went wrong in general and defines HTTP status of error.
+type Kind int
+
+const (
+	KindBadRequest Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindInternal
+)
+
+func (k Kind) HTTPStatus() int {
+	switch k {
+	case KindBadRequest, KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Lang is language of messages sent to client.
+type Lang string
+
+const (
+	LangRU Lang = "ru"
+	LangEN Lang = "en"
+
+	DefaultLang = LangRU
+)
+
+// Message is text of error in every supported language, it may be a format string.
+type Message struct {
+	RU string
+	EN string
+}
+
+// In returns text in lang, or in DefaultLang if there is no translation.
+func (m Message) In(lang Lang) string {
+	if lang == LangEN && m.EN != "" {
+		return m.EN
+	}
+
+	return m.RU
+}
+
+// Error is an error which is shown to client. Code is stable and machine-readable,
+// clients should branch on it instead of message. Details are sent to client as is.
 type Error struct {
-	err string
+	kind    Kind
+	code    string
+	message Message
+	args    []any
+	Details any
+}
+
+func newError(kind Kind, details any, code string, message Message, args ...any) *Error {
+	return &Error{kind: kind, code: code, message: message, args: args, Details: details}
+}
+
+// NewError returns error about bad request.
+func NewError(code string, message Message, args ...any) *Error {
+	return newError(KindBadRequest, nil, code, message, args...)
+}
+
+// NewValidationError is returned when input is invalid in several places at once.
+// Details describe every problem.
+func NewValidationError(details any, code string, message Message, args ...any) *Error {
+	return newError(KindValidation, details, code, message, args...)
+}
+
+// NewConflictError is returned when a write clashes with already stored data.
+// Details describe exactly what collides.
+func NewConflictError(details any, code string, message Message, args ...any) *Error {
+	return newError(KindConflict, details, code, message, args...)
+}
+
+// NewNotFoundError is returned when requested resource does not exist.
+func NewNotFoundError(code string, message Message, args ...any) *Error {
+	return newError(KindNotFound, nil, code, message, args...)
+}
+
+// NewUnauthorizedError is returned when client is not authenticated or its token is invalid.
+func NewUnauthorizedError(code string, message Message, args ...any) *Error {
+	return newError(KindUnauthorized, nil, code, message, args...)
+}
+
+// NewForbiddenError is returned when authenticated client is not allowed to do the action.
+func NewForbiddenError(code string, message Message, args ...any) *Error {
+	return newError(KindForbidden, nil, code, message, args...)
 }
 
-func NewError(format string, args ...any) *Error {
-	return &Error{fmt.Sprintf(format, args...)}
+// NewInternalError is returned when request failed because of server.
+func NewInternalError(code string, message Message) *Error {
+	return newError(KindInternal, nil, code, message)
 }
 
 func (e *Error) Error() string {
-	return e.err
+	return e.Message(DefaultLang)
+}
+
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+func (e *Error) Code() string {
+	return e.code
+}
+
+func (e *Error) HTTPStatus() int {
+	return e.kind.HTTPStatus()
+}
+
+// Message returns text of error in lang.
+func (e *Error) Message(lang Lang) string {
+	if len(e.args) == 0 {
+		return e.message.In(lang)
+	}
+
+	return fmt.Sprintf(e.message.In(lang), e.args...)
 }
@@ -0,0 +1,62 @@
+package my_errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestKindHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  *Error
+		want int
+	}{
+		{err: NewError("code", Message{RU: "ru", EN: "en"}), want: http.StatusBadRequest},
+		{err: NewValidationError(nil, "code", Message{RU: "ru", EN: "en"}), want: http.StatusBadRequest},
+		{err: NewUnauthorizedError("code", Message{RU: "ru", EN: "en"}), want: http.StatusUnauthorized},
+		{err: NewForbiddenError("code", Message{RU: "ru", EN: "en"}), want: http.StatusForbidden},
+		{err: NewNotFoundError("code", Message{RU: "ru", EN: "en"}), want: http.StatusNotFound},
+		{err: NewConflictError(nil, "code", Message{RU: "ru", EN: "en"}), want: http.StatusConflict},
+		{err: NewInternalError("code", Message{RU: "ru", EN: "en"}), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.HTTPStatus(); got != tt.want {
+			t.Errorf("HTTPStatus() of kind %d = %d, want %d", tt.err.Kind(), got, tt.want)
+		}
+	}
+
+	if got := Kind(100).HTTPStatus(); got != http.StatusInternalServerError {
+		t.Errorf("HTTPStatus() of unknown kind = %d, want %d", got, http.StatusInternalServerError)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	err := NewError("wrong_param", Message{RU: "плохой параметр %s=%d", EN: "bad param %s=%d"}, "id", 5)
+
+	if got := err.Message(LangEN); got != "bad param id=5" {
+		t.Errorf("Message(en) = %q", got)
+	}
+
+	if got := err.Error(); got != "плохой параметр id=5" {
+		t.Errorf("Error() = %q, want message in default language", got)
+	}
+
+	untranslated := NewError("no_translation", Message{RU: "только по-русски %"}) //nolint:exhaustruct
+
+	if got := untranslated.Message(LangEN); got != "только по-русски %" {
+		t.Errorf("Message(en) without translation = %q, want russian text unformatted", got)
+	}
+
+	wrapped := fmt.Errorf(ErrTemplate, err)
+
+	myErr := &Error{} //nolint:exhaustruct
+	if !errors.As(wrapped, &myErr) || myErr.Code() != "wrong_param" {
+		t.Errorf("code of wrapped error is lost: %v", wrapped)
+	}
+}
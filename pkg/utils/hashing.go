@@ -3,6 +3,8 @@ package utils
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	myerrors "github.com/SanExpett/banners-backend/pkg/my_errors"
@@ -53,3 +55,21 @@ func ComparePassAndHash(passHash []byte, plainPassword string) bool {
 
 	return bytes.Equal(userPassHash, passHash)
 }
+
+// RandomString returns url safe string of n random bytes.
+func RandomString(n int) (string, error) {
+	randomBytes := make([]byte, n)
+
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf(myerrors.ErrTemplate, err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+// HashToken returns sha256 of token. Tokens are random enough to be stored without salt.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
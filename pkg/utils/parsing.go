@@ -8,8 +8,16 @@ import (
 	"strconv"
 )
 
-var MessageErrWrongNumberParam = "Получили некорректный числовой параметр. " + //nolint:gochecknoglobals
-	"Он должен быть целым"
+var (
+	MessageErrWrongNumberParam = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Получили некорректный числовой параметр. Он должен быть целым: %s=%s",
+		EN: "Got invalid number parameter. It must be an integer: %s=%s",
+	}
+	MessageErrWrongBoolParam = myerrors.Message{ //nolint:gochecknoglobals
+		RU: "Получили некорректный логический параметр. Он должен быть true или false: %s=%s",
+		EN: "Got invalid bool parameter. It must be true or false: %s=%s",
+	}
+)
 
 func ParseUint64FromRequest(r *http.Request, paramName string) (uint64, error) {
 	logger, err := mylogger.Get()
@@ -21,7 +29,7 @@ func ParseUint64FromRequest(r *http.Request, paramName string) (uint64, error) {
 
 	number, err := strconv.ParseUint(numberStr, 10, 64)
 	if err != nil {
-		err := fmt.Errorf("%s %s=%s", MessageErrWrongNumberParam, paramName, numberStr)
+		err := myerrors.NewError("wrong_number_param", MessageErrWrongNumberParam, paramName, numberStr)
 
 		logger.Errorln(err)
 
@@ -34,3 +42,25 @@ func ParseUint64FromRequest(r *http.Request, paramName string) (uint64, error) {
 func ParseStringFromRequest(r *http.Request, paramName string) string {
 	return r.URL.Query().Get(paramName)
 }
+
+// ParseBoolFromRequest returns false if param is absent or is not a bool.
+func ParseBoolFromRequest(r *http.Request, paramName string) bool {
+	value, err := strconv.ParseBool(r.URL.Query().Get(paramName))
+	if err != nil {
+		return false
+	}
+
+	return value
+}
+
+// ParseRequiredBoolFromRequest is like ParseBoolFromRequest, but absent or invalid param is an error.
+func ParseRequiredBoolFromRequest(r *http.Request, paramName string) (bool, error) {
+	valueStr := r.URL.Query().Get(paramName)
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return false, myerrors.NewError("wrong_bool_param", MessageErrWrongBoolParam, paramName, valueStr)
+	}
+
+	return value, nil
+}